@@ -1,7 +1,16 @@
 package apirouter
 
+import "github.com/davidebianchi/gswagger/security"
+
 type Router[HandlerFunc any, Route any] interface {
 	AddRoute(method string, path string, handler HandlerFunc) Route
 	SwaggerHandler(contentType string, blob []byte) HandlerFunc
 	TransformPathToOasPath(path string) string
 }
+
+// SecurityRouter is implemented by the routers which can enforce the security
+// policy of the routes. The returned handler calls the given one only if the
+// request satisfies the policy, otherwise it replies with the security.Problem.
+type SecurityRouter[HandlerFunc any] interface {
+	WithSecurity(handler HandlerFunc, policy security.Policy) HandlerFunc
+}
@@ -0,0 +1,195 @@
+package swagger
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	parameterComponentsPrefix   = "#/components/parameters/"
+	requestBodyComponentsPrefix = "#/components/requestBodies/"
+	responseComponentsPrefix    = "#/components/responses/"
+	headerComponentsPrefix      = "#/components/headers/"
+)
+
+// AddParameterComponent adds to the components parameters the parameter in the
+// given location (path, query, header or cookie), whose name is also the name
+// of the component. The routes reference it through the Definitions ParameterRefs.
+func (r Router[_, _]) AddParameterComponent(name string, in string, parameter Parameter) error {
+	if err := validateComponentName(name); err != nil {
+		return err
+	}
+	components := r.components()
+	if components.Parameters[name] != nil {
+		return fmt.Errorf("parameter component %s is already defined", name)
+	}
+	param, err := r.newParameter(in, name, parameter)
+	if err != nil {
+		return fmt.Errorf("parameter component %s: %w", name, err)
+	}
+	if components.Parameters == nil {
+		components.Parameters = openapi3.ParametersMap{}
+	}
+	components.Parameters[name] = &openapi3.ParameterRef{Value: param}
+	return nil
+}
+
+// AddRequestBodyComponent adds the request body to the components request
+// bodies. The routes reference it setting its name as the Ref of the RequestBody.
+func (r Router[_, _]) AddRequestBodyComponent(name string, requestBody ContentValue) error {
+	if err := validateComponentName(name); err != nil {
+		return err
+	}
+	components := r.components()
+	if components.RequestBodies[name] != nil {
+		return fmt.Errorf("request body component %s is already defined", name)
+	}
+	if requestBody.Ref != "" {
+		return fmt.Errorf("request body component %s: ref is not supported", name)
+	}
+	oasRequestBody, err := r.newRequestBody(requestBody)
+	if err != nil {
+		return fmt.Errorf("request body component %s: %w", name, err)
+	}
+	if components.RequestBodies == nil {
+		components.RequestBodies = openapi3.RequestBodies{}
+	}
+	components.RequestBodies[name] = &openapi3.RequestBodyRef{Value: oasRequestBody}
+	return nil
+}
+
+// AddResponseComponent adds the response to the components responses. The
+// routes reference it setting its name as the Ref of a response.
+func (r Router[_, _]) AddResponseComponent(name string, response ContentValue) error {
+	if err := validateComponentName(name); err != nil {
+		return err
+	}
+	if r.components().Responses[name] != nil {
+		return fmt.Errorf("response component %s is already defined", name)
+	}
+	if response.Ref != "" {
+		return fmt.Errorf("response component %s: ref is not supported", name)
+	}
+	oasResponse, err := r.newResponse(response)
+	if err != nil {
+		return fmt.Errorf("response component %s: %w", name, err)
+	}
+	r.setResponseComponent(name, oasResponse)
+	return nil
+}
+
+// AddHeaderComponent adds the header to the components headers. The responses
+// reference it setting its name as the Ref of a header.
+func (r Router[_, _]) AddHeaderComponent(name string, header Header) error {
+	if err := validateComponentName(name); err != nil {
+		return err
+	}
+	components := r.components()
+	if components.Headers[name] != nil {
+		return fmt.Errorf("header component %s is already defined", name)
+	}
+	if header.Ref != "" {
+		return fmt.Errorf("header component %s: ref is not supported", name)
+	}
+	oasHeader, err := r.newHeader(header)
+	if err != nil {
+		return fmt.Errorf("header component %s: %w", name, err)
+	}
+	if components.Headers == nil {
+		components.Headers = openapi3.Headers{}
+	}
+	components.Headers[name] = &openapi3.HeaderRef{Value: oasHeader}
+	return nil
+}
+
+// components returns the components of the openapi schema, creating them if
+// they are not set.
+func (r Router[_, _]) components() *openapi3.Components {
+	if r.swaggerSchema.Components == nil {
+		r.swaggerSchema.Components = &openapi3.Components{}
+	}
+	return r.swaggerSchema.Components
+}
+
+// stageComponents returns a copy of the router which adds the schema and the
+// response components to a copy of the router ones, and the function which
+// sets them in the openapi schema. It is called once all the checks of a route
+// passed, so that an invalid route leaves no components in the openapi schema.
+func (r Router[HandlerFunc, Route]) stageComponents() (Router[HandlerFunc, Route], func()) {
+	var components openapi3.Components
+	if r.swaggerSchema.Components != nil {
+		components = *r.swaggerSchema.Components
+	}
+	components.Schemas = maps.Clone(components.Schemas)
+	components.Responses = maps.Clone(components.Responses)
+	document := *r.swaggerSchema
+	document.Components = &components
+
+	staged := r
+	staged.swaggerSchema = &document
+	staged.schemaTypes = maps.Clone(r.schemaTypes)
+	return staged, func() {
+		if r.swaggerSchema.Components == nil && reflect.ValueOf(components).IsZero() {
+			return
+		}
+		*r.components() = components
+		maps.Copy(r.schemaTypes, staged.schemaTypes)
+	}
+}
+
+func (r Router[_, _]) setResponseComponent(name string, response *openapi3.Response) {
+	components := r.components()
+	if components.Responses == nil {
+		components.Responses = openapi3.ResponseBodies{}
+	}
+	components.Responses[name] = &openapi3.ResponseRef{Value: response}
+}
+
+func (r Router[_, _]) parameterComponentRefs(names []string) (openapi3.Parameters, error) {
+	if names == nil {
+		return nil, nil
+	}
+	parameters := make(openapi3.Parameters, 0, len(names))
+	for _, name := range names {
+		component := r.components().Parameters[name]
+		if component == nil {
+			return nil, fmt.Errorf("unknown parameter component %s", name)
+		}
+		parameters = append(parameters, &openapi3.ParameterRef{Ref: parameterComponentsPrefix + name, Value: component.Value})
+	}
+	return parameters, nil
+}
+
+func (r Router[_, _]) requestBodyComponentRef(name string) (*openapi3.RequestBodyRef, error) {
+	component := r.components().RequestBodies[name]
+	if component == nil {
+		return nil, fmt.Errorf("unknown request body component %s", name)
+	}
+	return &openapi3.RequestBodyRef{Ref: requestBodyComponentsPrefix + name, Value: component.Value}, nil
+}
+
+func (r Router[_, _]) responseComponentRef(name string) (*openapi3.ResponseRef, error) {
+	component := r.components().Responses[name]
+	if component == nil {
+		return nil, fmt.Errorf("unknown response component %s", name)
+	}
+	return &openapi3.ResponseRef{Ref: responseComponentsPrefix + name, Value: component.Value}, nil
+}
+
+func (r Router[_, _]) headerComponentRef(name string) (*openapi3.HeaderRef, error) {
+	component := r.components().Headers[name]
+	if component == nil {
+		return nil, fmt.Errorf("unknown header component %s", name)
+	}
+	return &openapi3.HeaderRef{Ref: headerComponentsPrefix + name, Value: component.Value}, nil
+}
+
+func validateComponentName(name string) error {
+	if name == "" {
+		return fmt.Errorf("component name is required")
+	}
+	return openapi3.ValidateIdentifier(name)
+}
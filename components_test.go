@@ -0,0 +1,166 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponents(t *testing.T) {
+	newRouter := func(t *testing.T, r *mux.Router) *TestRouter {
+		t.Helper()
+		router := newTestRouter(t, r, Options{})
+
+		require.NoError(t, router.AddParameterComponent("X-Tenant-ID", headerParamType, Parameter{
+			Schema:   &Schema{Value: ""},
+			Required: true,
+		}))
+		require.NoError(t, router.AddParameterComponent("page", queryParamType, Parameter{
+			Schema:      &Schema{Value: 0},
+			Description: "the page number",
+		}))
+		require.NoError(t, router.AddRequestBodyComponent("User", ContentValue{
+			Content: Content{
+				jsonType: {Value: componentUser{}},
+			},
+		}))
+		require.NoError(t, router.AddHeaderComponent("X-Request-ID", Header{
+			Schema:      &Schema{Value: ""},
+			Description: "the id of the request",
+		}))
+		require.NoError(t, router.AddResponseComponent("NotFound", ContentValue{
+			Description: "resource not found",
+			Headers: map[string]Header{
+				"X-Request-ID": {Ref: "X-Request-ID"},
+			},
+		}))
+		return router
+	}
+
+	t.Run("reference components", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newRouter(t, r)
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			ParameterRefs: []string{"X-Tenant-ID", "page"},
+			Responses: map[int]ContentValue{
+				http.StatusOK: {
+					Content: Content{
+						jsonType: {Value: []componentUser{}},
+					},
+				},
+				http.StatusNotFound: {Ref: "NotFound"},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			ParameterRefs: []string{"X-Tenant-ID"},
+			RequestBody:   &ContentValue{Ref: "User"},
+			Responses: map[int]ContentValue{
+				http.StatusCreated: {
+					Headers: map[string]Header{
+						"X-Request-ID": {Ref: "X-Request-ID"},
+					},
+				},
+			},
+			DefaultResponse: &ContentValue{Ref: "NotFound"},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/reusable-components.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("unknown components", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter())
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			ParameterRefs: []string{"limit"},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /users: parameter refs: unknown parameter component limit", ErrParameters))
+
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{Ref: "Users"},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: POST /users: request body: unknown request body component Users", ErrRequestBody))
+
+		_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			Responses: map[int]ContentValue{
+				http.StatusBadRequest: {Ref: "BadRequest"},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /users: response 400: unknown response component BadRequest", ErrResponses))
+
+		_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			Responses: map[int]ContentValue{
+				http.StatusOK: {
+					Headers: map[string]Header{
+						"X-Trace-ID": {Ref: "X-Trace-ID"},
+					},
+				},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /users: response 200: header X-Trace-ID: unknown header component X-Trace-ID", ErrResponses))
+	})
+
+	t.Run("parameter already defined by the route", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter())
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			ParameterRefs: []string{"page"},
+			Querystring: ParameterValue{
+				"page": {Schema: &Schema{Value: 0}},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /users: query parameter page: parameter is already defined", ErrParameters))
+	})
+
+	t.Run("invalid components", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter())
+
+		err := router.AddParameterComponent("page", queryParamType, Parameter{})
+		require.EqualError(t, err, "parameter component page is already defined")
+
+		err = router.AddParameterComponent("limit", "body", Parameter{})
+		require.EqualError(t, err, "parameter component limit: invalid param type")
+
+		err = router.AddRequestBodyComponent("User", ContentValue{})
+		require.EqualError(t, err, "request body component User is already defined")
+
+		err = router.AddRequestBodyComponent("Users", ContentValue{
+			Headers: map[string]Header{"X-Request-ID": {}},
+		})
+		require.EqualError(t, err, "request body component Users: headers and links are supported only in responses")
+
+		err = router.AddResponseComponent("NotFound", ContentValue{})
+		require.EqualError(t, err, "response component NotFound is already defined")
+
+		err = router.AddResponseComponent("Gone", ContentValue{Ref: "NotFound"})
+		require.EqualError(t, err, "response component Gone: ref is not supported")
+
+		err = router.AddHeaderComponent("X-Request-ID", Header{})
+		require.EqualError(t, err, "header component X-Request-ID is already defined")
+
+		err = router.AddHeaderComponent("", Header{})
+		require.EqualError(t, err, "component name is required")
+
+		err = router.AddHeaderComponent("X Trace", Header{})
+		require.ErrorContains(t, err, `identifier "X Trace" is not supported`)
+	})
+}
@@ -0,0 +1,303 @@
+package swagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RouteError is the error returned by AddRoute and AddWebhook if a part of the
+// definitions is not valid, and collected by GenerateAndExposeOpenapi with the
+// AggregateValidationErrors option. It matches, with errors.Is, the error of
+// the part (e.g. ErrRequestBody) and its cause.
+type RouteError struct {
+	Method string
+	// Path is the openapi path of the route, with the router prefix, or the
+	// name of the webhook.
+	Path string
+	// Section is the part of the definitions with the error, e.g. request body,
+	// response 200 or query parameter page.
+	Section string
+	// Err is the cause of the error.
+	Err error
+
+	kind error
+}
+
+func (e *RouteError) Error() string {
+	message := fmt.Sprintf("%s %s: %s: %s", e.Method, e.Path, e.Section, e.Err)
+	if e.kind == nil {
+		return message
+	}
+	return fmt.Sprintf("%s: %s", e.kind, message)
+}
+
+// Unwrap returns the error of the part of the definitions and the cause.
+func (e *RouteError) Unwrap() []error {
+	if e.kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.kind, e.Err}
+}
+
+// sectionError is the error of a section of the definitions, converted by
+// newRouteError into the RouteError section.
+type sectionError struct {
+	section string
+	err     error
+}
+
+func (e *sectionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.section, e.err)
+}
+
+func (e *sectionError) Unwrap() error {
+	return e.err
+}
+
+// newRouteError returns the RouteError of the given kind. The section is the
+// one of the sectionError, if err is one, or the given one. The method and the
+// path are set by setRoute.
+func newRouteError(kind error, section string, err error) *RouteError {
+	var sectionErr *sectionError
+	if errors.As(err, &sectionErr) {
+		section, err = sectionErr.section, sectionErr.err
+	}
+	return &RouteError{Section: section, Err: err, kind: kind}
+}
+
+// setRoute sets the method and the path of the RouteError, if err is one.
+func setRoute(err error, method, path string) error {
+	var routeErr *RouteError
+	if errors.As(err, &routeErr) {
+		routeErr.Method = method
+		routeErr.Path = path
+	}
+	return err
+}
+
+// ValidationErrors contains all the errors found validating the openapi
+// document, returned by GenerateAndExposeOpenapi with the AggregateValidationErrors option.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d errors:\n%s", len(e), strings.Join(messages, "\n"))
+}
+
+// Unwrap returns the errors.
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// collectValidationErrors validates the openapi document collecting all the
+// errors: the ones of each component and of each other part of the document,
+// and the ones of each section of the operations of the routes and of the
+// webhooks.
+func (r Router[_, _]) collectValidationErrors(opts ...openapi3.ValidationOption) ValidationErrors {
+	errs := r.collectDocumentErrors(opts...)
+
+	paths := r.swaggerSchema.Paths.Map()
+	oasPaths := make([]string, 0, len(paths))
+	for oasPath := range paths {
+		oasPaths = append(oasPaths, oasPath)
+	}
+	sort.Strings(oasPaths)
+	for _, oasPath := range oasPaths {
+		errs = append(errs, r.collectPathItemErrors(oasPath, paths[oasPath], true, opts...)...)
+	}
+
+	names := make([]string, 0, len(r.webhooks))
+	for name := range r.webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, r.collectPathItemErrors(name, r.webhooks[name], false, opts...)...)
+	}
+
+	if err := r.checkSecurityRequirements(r.swaggerSchema.Security); err != nil {
+		errs = append(errs, fmt.Errorf("default security: %w", err))
+	}
+	if r.onUndeclaredTag != nil {
+		for _, tag := range r.undeclaredTags() {
+			if err := r.onUndeclaredTag(tag); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// collectDocumentErrors validates the openapi document without the paths. Each
+// component and each server are validated apart, as the tags and the external
+// docs. The rest of the document (e.g. the info) is validated at last.
+func (r Router[_, _]) collectDocumentErrors(opts ...openapi3.ValidationOption) ValidationErrors {
+	ctx := openapi3.WithValidationOptions(r.context, opts...)
+	var errs ValidationErrors
+
+	document := *r.swaggerSchema
+	document.Paths = openapi3.NewPaths()
+	if components := document.Components; components != nil {
+		errs = append(errs, collectComponentErrors(ctx, components.Schemas, func(c *openapi3.Components, v openapi3.Schemas) { c.Schemas = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.Parameters, func(c *openapi3.Components, v openapi3.ParametersMap) { c.Parameters = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.Headers, func(c *openapi3.Components, v openapi3.Headers) { c.Headers = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.RequestBodies, func(c *openapi3.Components, v openapi3.RequestBodies) { c.RequestBodies = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.Responses, func(c *openapi3.Components, v openapi3.ResponseBodies) { c.Responses = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.SecuritySchemes, func(c *openapi3.Components, v openapi3.SecuritySchemes) { c.SecuritySchemes = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.Examples, func(c *openapi3.Components, v openapi3.Examples) { c.Examples = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.Links, func(c *openapi3.Components, v openapi3.Links) { c.Links = v })...)
+		errs = append(errs, collectComponentErrors(ctx, components.Callbacks, func(c *openapi3.Components, v openapi3.Callbacks) { c.Callbacks = v })...)
+		document.Components = &openapi3.Components{Extensions: components.Extensions}
+	}
+
+	for i, server := range document.Servers {
+		if err := server.Validate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("invalid servers: server %d: %w", i, err))
+		}
+	}
+	if err := document.Tags.Validate(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("invalid tags: %w", err))
+	}
+	if document.ExternalDocs != nil {
+		if err := document.ExternalDocs.Validate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("invalid external docs: %w", err))
+		}
+	}
+	document.Servers = nil
+	document.Tags = nil
+	document.ExternalDocs = nil
+	if err := document.Validate(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// collectComponentErrors validates each of the components, set alone in the
+// components by set.
+func collectComponentErrors[M ~map[string]V, V any](ctx context.Context, components M, set func(c *openapi3.Components, v M)) ValidationErrors {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs ValidationErrors
+	for _, name := range names {
+		var component openapi3.Components
+		set(&component, M{name: components[name]})
+		if err := component.Validate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("invalid components: %w", err))
+		}
+	}
+	return errs
+}
+
+// collectPathItemErrors returns a RouteError for each invalid section of the
+// operations of the path item. The path parameters are checked only for the
+// routes, whose operations have no other error. The examples are validated for
+// the valid sections.
+func (r Router[_, _]) collectPathItemErrors(path string, pathItem *openapi3.PathItem, isRoute bool, opts ...openapi3.ValidationOption) ValidationErrors {
+	ctx := openapi3.WithValidationOptions(r.context, opts...)
+	var errs ValidationErrors
+	operations := pathItem.Operations()
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		operation := operations[method]
+		operationErrs := operationErrors(ctx, operation)
+		if len(operationErrs) == 0 && isRoute {
+			operationPathItem := &openapi3.PathItem{Parameters: pathItem.Parameters}
+			operationPathItem.SetOperation(method, operation)
+			operationPaths := openapi3.NewPaths(openapi3.WithPath(path, operationPathItem))
+			if err := operationPaths.Validate(ctx); err != nil {
+				operationErrs = append(operationErrs, &sectionError{section: "path parameters", err: err})
+			}
+		}
+
+		invalidSections := map[string]bool{}
+		for _, err := range operationErrs {
+			routeErr := newRouteError(nil, "operation", err)
+			invalidSections[routeErr.Section] = true
+			errs = append(errs, setRoute(routeErr, method, path))
+		}
+		if invalidSections["operation"] || invalidSections["path parameters"] {
+			continue
+		}
+		for _, err := range operationExamplesErrors(operation) {
+			routeErr := newRouteError(nil, "examples", err)
+			if !invalidSections[routeErr.Section] {
+				errs = append(errs, setRoute(routeErr, method, path))
+			}
+		}
+	}
+	return errs
+}
+
+// operationErrors validates the operation, returning a sectionError for each
+// invalid parameter and response and for the invalid request body. The other
+// errors of the operation have no section.
+func operationErrors(ctx context.Context, operation *openapi3.Operation) []error {
+	var errs []error
+	rest := *operation
+
+	for _, parameter := range operation.Parameters {
+		if err := parameter.Validate(ctx); err != nil {
+			errs = append(errs, &sectionError{section: parameterSection(parameter), err: err})
+		}
+	}
+	// The valid parameters are validated again with the rest of the operation,
+	// to check that they are not duplicated.
+	if len(errs) > 0 {
+		rest.Parameters = nil
+	}
+
+	if operation.RequestBody != nil {
+		if err := operation.RequestBody.Validate(ctx); err != nil {
+			errs = append(errs, &sectionError{section: "request body", err: err})
+		}
+		rest.RequestBody = nil
+	}
+
+	if operation.Responses.Len() > 0 {
+		responses := operation.Responses.Map()
+		statusCodes := make([]string, 0, len(responses))
+		for statusCode := range responses {
+			statusCodes = append(statusCodes, statusCode)
+		}
+		sort.Strings(statusCodes)
+		for _, statusCode := range statusCodes {
+			if err := responses[statusCode].Validate(ctx); err != nil {
+				errs = append(errs, &sectionError{section: "response " + statusCode, err: err})
+			}
+		}
+		// The responses are replaced by a valid one, to validate the rest of the
+		// operation without them.
+		rest.Responses = openapi3.NewResponses()
+		rest.Responses.Extensions = operation.Responses.Extensions
+	}
+
+	if err := rest.Validate(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// parameterSection returns the section of the parameter, e.g. query parameter page.
+func parameterSection(parameter *openapi3.ParameterRef) string {
+	if parameter.Value == nil {
+		return "parameter " + parameter.Ref
+	}
+	return fmt.Sprintf("%s parameter %s", parameter.Value.In, parameter.Value.Name)
+}
@@ -0,0 +1,309 @@
+package swagger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/support/gorilla"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteError(t *testing.T) {
+	invalidParameter := Parameter{
+		Example:  "ten",
+		Examples: Examples{"ten": {Value: "ten"}},
+	}
+
+	tests := []struct {
+		name            string
+		definitions     Definitions
+		expectedKind    error
+		expectedSection string
+		expectedCause   string
+	}{
+		{
+			name: "path parameter",
+			definitions: Definitions{
+				PathParams: ParameterValue{"userId": invalidParameter},
+			},
+			expectedKind:    ErrPathParams,
+			expectedSection: "path parameter userId",
+			expectedCause:   "example and examples are mutually exclusive",
+		},
+		{
+			name: "query parameter",
+			definitions: Definitions{
+				Querystring: ParameterValue{"limit": invalidParameter},
+			},
+			expectedKind:    ErrQuerystring,
+			expectedSection: "query parameter limit",
+			expectedCause:   "example and examples are mutually exclusive",
+		},
+		{
+			name: "header parameter",
+			definitions: Definitions{
+				Headers: ParameterValue{"X-Limit": invalidParameter},
+			},
+			expectedKind:    ErrHeaders,
+			expectedSection: "header parameter X-Limit",
+			expectedCause:   "example and examples are mutually exclusive",
+		},
+		{
+			name: "cookie parameter",
+			definitions: Definitions{
+				Cookies: ParameterValue{"limit": invalidParameter},
+			},
+			expectedKind:    ErrCookies,
+			expectedSection: "cookie parameter limit",
+			expectedCause:   "example and examples are mutually exclusive",
+		},
+		{
+			name: "request body",
+			definitions: Definitions{
+				RequestBody: &ContentValue{Ref: "User"},
+			},
+			expectedKind:    ErrRequestBody,
+			expectedSection: "request body",
+			expectedCause:   "unknown request body component User",
+		},
+		{
+			name: "response",
+			definitions: Definitions{
+				Responses: map[int]ContentValue{
+					http.StatusOK: {Headers: map[string]Header{"X-Count": {Ref: "X-Count"}}},
+				},
+			},
+			expectedKind:    ErrResponses,
+			expectedSection: "response 200",
+			expectedCause:   "header X-Count: unknown header component X-Count",
+		},
+		{
+			name: "security",
+			definitions: Definitions{
+				Security: SecurityRequirements{{"jwt": {}}},
+			},
+			expectedKind:    ErrSecurity,
+			expectedSection: "security",
+			expectedCause:   "unknown security scheme jwt",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			router := newTestRouter(t, r, Options{})
+			subRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{PathPrefix: "/v1"})
+			require.NoError(t, err)
+
+			_, err = subRouter.AddRoute(http.MethodPost, "/users/{userId}", okHandler, test.definitions)
+			require.ErrorIs(t, err, test.expectedKind)
+			var routeErr *RouteError
+			require.ErrorAs(t, err, &routeErr)
+			require.Equal(t, http.MethodPost, routeErr.Method)
+			require.Equal(t, "/v1/users/{userId}", routeErr.Path)
+			require.Equal(t, test.expectedSection, routeErr.Section)
+			require.EqualError(t, routeErr.Err, test.expectedCause)
+			require.EqualError(t, err, fmt.Sprintf("%s: POST /v1/users/{userId}: %s: %s", test.expectedKind, test.expectedSection, test.expectedCause))
+		})
+	}
+
+	t.Run("webhook", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			Openapi31: true,
+		})
+
+		err := router.AddWebhook("userCreated", http.MethodPost, Definitions{
+			RequestBody: &ContentValue{Ref: "User"},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: POST userCreated: request body: unknown request body component User", ErrRequestBody))
+	})
+
+	t.Run("invalid raw operation", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		_, err := router.AddRawRoute(http.MethodGet, "/users", okHandler, Operation{&openapi3.Operation{}})
+		var routeErr *RouteError
+		require.ErrorAs(t, err, &routeErr)
+		require.Equal(t, http.MethodGet, routeErr.Method)
+		require.Equal(t, "/users", routeErr.Path)
+		require.Equal(t, "operation", routeErr.Section)
+		require.EqualError(t, err, "GET /users: operation: value of responses must be an object")
+	})
+
+	t.Run("duplicate route", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+		_, err := router.AddRoute(http.MethodGet, "/users/{id}", okHandler, Definitions{})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodGet, "/users/{userId}", okHandler, Definitions{})
+		var routeErr *RouteError
+		require.ErrorAs(t, err, &routeErr)
+		require.Equal(t, http.MethodGet, routeErr.Method)
+		require.Equal(t, "/users/{userId}", routeErr.Path)
+		require.Equal(t, "route", routeErr.Section)
+		var duplicateErr *DuplicateRouteError
+		require.ErrorAs(t, err, &duplicateErr)
+		require.Equal(t, "/users/{id}", duplicateErr.RegisteredPath)
+		require.ErrorIs(t, err, ErrDuplicateRoute)
+	})
+
+	t.Run("duplicate operation id", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{OperationID: "listUsers"})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodGet, "/v2/users", okHandler, Definitions{OperationID: "listUsers"})
+		var routeErr *RouteError
+		require.ErrorAs(t, err, &routeErr)
+		require.Equal(t, http.MethodGet, routeErr.Method)
+		require.Equal(t, "/v2/users", routeErr.Path)
+		require.Equal(t, "operation id", routeErr.Section)
+		require.ErrorIs(t, err, ErrOperationID)
+	})
+
+	t.Run("cause matched by errors.Is", func(t *testing.T) {
+		errInvalidTag := errors.New("invalid tag")
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			ValidateTags: true,
+			OnUnsupportedValidateTag: func(field reflect.StructField, tag string) error {
+				return errInvalidTag
+			},
+		})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: struct {
+						Color string `json:"color" validate:"rgb"`
+					}{}},
+				},
+			},
+		})
+		require.ErrorIs(t, err, ErrRequestBody)
+		require.ErrorIs(t, err, errInvalidTag)
+	})
+}
+
+func TestAggregateValidationErrors(t *testing.T) {
+	addRoutes := func(t *testing.T, options Options) *TestRouter {
+		t.Helper()
+		options.Security = SecurityRequirements{{"jwt": {}}}
+		options.OnUndeclaredTag = RejectUndeclaredTags
+		router := newTestRouter(t, mux.NewRouter(), options)
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			Tags:     []string{"users"},
+			Security: NoSecurity(),
+			Querystring: ParameterValue{
+				"limit": {Schema: &Schema{Value: 0}, Example: "ten"},
+			},
+		})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			Security: NoSecurity(),
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: exampleUser{}, Example: map[string]interface{}{"age": 1}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		_, err = router.AddRawRoute(http.MethodDelete, "/users/{userId}", okHandler, Operation{&openapi3.Operation{
+			Responses: openapi3.NewResponses(),
+		}})
+		require.NoError(t, err)
+		return router
+	}
+
+	t.Run("reports the first error by default", func(t *testing.T) {
+		router := addRoutes(t, Options{})
+
+		err := router.GenerateAndExposeOpenapi()
+		require.ErrorIs(t, err, ErrValidatingOAS)
+		var errs ValidationErrors
+		require.False(t, errors.As(err, &errs))
+	})
+
+	t.Run("reports all the errors", func(t *testing.T) {
+		router := addRoutes(t, Options{AggregateValidationErrors: true})
+
+		err := router.GenerateAndExposeOpenapi()
+		require.ErrorIs(t, err, ErrValidatingOAS)
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 5)
+
+		var routeErr *RouteError
+		require.ErrorAs(t, errs[0], &routeErr)
+		require.Equal(t, http.MethodGet, routeErr.Method)
+		require.Equal(t, "/users", routeErr.Path)
+		require.Equal(t, "query parameter limit", routeErr.Section)
+		require.ErrorContains(t, routeErr.Err, "invalid example: value must be an integer")
+
+		require.ErrorAs(t, errs[1], &routeErr)
+		require.Equal(t, http.MethodPost, routeErr.Method)
+		require.Equal(t, "request body", routeErr.Section)
+		require.ErrorContains(t, routeErr.Err, "content application/json: invalid example: Error at \"/name\": property \"name\" is missing")
+
+		require.ErrorAs(t, errs[2], &routeErr)
+		require.Equal(t, http.MethodDelete, routeErr.Method)
+		require.Equal(t, "/users/{userId}", routeErr.Path)
+		require.Equal(t, "path parameters", routeErr.Section)
+
+		require.EqualError(t, errs[3], "default security: unknown security scheme jwt")
+		require.EqualError(t, errs[4], "tag users is used but not declared")
+		require.ErrorContains(t, err, fmt.Sprintf("%s: 5 errors:\nGET /users: query parameter limit: invalid example: value must be an integer\n", ErrValidatingOAS))
+	})
+
+	t.Run("reports each invalid section and component", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{AggregateValidationErrors: true})
+
+		notFound := openapi3.NewResponse().WithDescription("not found").WithJSONSchema(openapi3.NewIntegerSchema())
+		notFound.Content.Get(jsonType).Example = "none"
+		responses := openapi3.NewResponses(
+			openapi3.WithStatus(http.StatusOK, &openapi3.ResponseRef{Value: &openapi3.Response{}}),
+			openapi3.WithStatus(http.StatusNotFound, &openapi3.ResponseRef{Value: notFound}),
+		)
+		// The invalid parts are set in the document, since AddRawRoute rejects them.
+		router.swaggerSchema.AddOperation("/users", http.MethodGet, &openapi3.Operation{
+			Parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("limit")},
+				{Value: openapi3.NewQueryParameter("offset").WithSchema(openapi3.NewIntegerSchema())},
+				{Value: openapi3.NewQueryParameter("sort")},
+			},
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody()},
+			Responses:   responses,
+		})
+		router.swaggerSchema.Components = &openapi3.Components{
+			Schemas: openapi3.Schemas{
+				"Invalid":      openapi3.NewSchemaRef("", &openapi3.Schema{Type: &openapi3.Types{"text"}}),
+				"Valid":        openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+				"invalid name": openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+			},
+		}
+		router.swaggerSchema.Servers = openapi3.Servers{{URL: ""}}
+
+		err := router.GenerateAndExposeOpenapi()
+		var errs ValidationErrors
+		require.ErrorAs(t, err, &errs)
+		require.Len(t, errs, 8)
+		require.EqualError(t, errs[0], `invalid components: schema "Invalid": unsupported 'type' value "text"`)
+		require.ErrorContains(t, errs[1], `invalid components: schema "invalid name": identifier "invalid name" is not supported`)
+		require.EqualError(t, errs[2], "invalid servers: server 0: value of url must be a non-empty string")
+
+		sections := []string{"query parameter limit", "query parameter sort", "request body", "response 200", "response 404"}
+		for i, section := range sections {
+			var routeErr *RouteError
+			require.ErrorAs(t, errs[i+3], &routeErr)
+			require.Equal(t, http.MethodGet, routeErr.Method)
+			require.Equal(t, "/users", routeErr.Path)
+			require.Equal(t, section, routeErr.Section)
+		}
+		require.ErrorContains(t, errs[7], "GET /users: response 404: content application/json: invalid example: value must be an integer")
+	})
+}
@@ -0,0 +1,252 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Example of a content or of a parameter. The Value is marshalled in json.
+type Example struct {
+	Summary     string
+	Description string
+	Value       interface{}
+}
+
+// Examples contains the examples by name.
+type Examples map[string]Example
+
+// marshalExamples returns the json value of the example and the openapi examples.
+func marshalExamples(example interface{}, examples Examples) (interface{}, openapi3.Examples, error) {
+	if example != nil && examples != nil {
+		return nil, nil, fmt.Errorf("example and examples are mutually exclusive")
+	}
+
+	exampleValue, err := marshalExampleValue(example)
+	if err != nil {
+		return nil, nil, fmt.Errorf("example: %w", err)
+	}
+	if examples == nil {
+		return exampleValue, nil, nil
+	}
+
+	oasExamples := make(openapi3.Examples, len(examples))
+	for name, example := range examples {
+		value, err := marshalExampleValue(example.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("example %s: %w", name, err)
+		}
+		oasExample := openapi3.NewExample(value)
+		oasExample.Summary = example.Summary
+		oasExample.Description = example.Description
+		oasExamples[name] = &openapi3.ExampleRef{Value: oasExample}
+	}
+	return nil, oasExamples, nil
+}
+
+func marshalExampleValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// setContentExamples sets the example and the examples in the media types
+// of the content which have not their own.
+func setContentExamples(content openapi3.Content, example interface{}, examples Examples) error {
+	if example == nil && examples == nil {
+		return nil
+	}
+	exampleValue, oasExamples, err := marshalExamples(example, examples)
+	if err != nil {
+		return err
+	}
+	for _, mediaType := range content {
+		if mediaType.Example == nil && mediaType.Examples == nil {
+			mediaType.Example = exampleValue
+			mediaType.Examples = oasExamples
+		}
+	}
+	return nil
+}
+
+// validateExamples validates the examples of the routes and of the webhooks
+// against their schemas.
+func (r Router[_, _]) validateExamples() error {
+	paths := r.swaggerSchema.Paths.Map()
+	oasPaths := make([]string, 0, len(paths))
+	for oasPath := range paths {
+		oasPaths = append(oasPaths, oasPath)
+	}
+	sort.Strings(oasPaths)
+	for _, oasPath := range oasPaths {
+		if err := validatePathItemExamples("route", oasPath, paths[oasPath]); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(r.webhooks))
+	for name := range r.webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validatePathItemExamples("webhook", name, r.webhooks[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validatePathItemExamples(kind, name string, pathItem *openapi3.PathItem) error {
+	operations := pathItem.Operations()
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		if err := validateOperationExamples(operations[method]); err != nil {
+			return fmt.Errorf("%s %s %s: %w", kind, method, name, err)
+		}
+	}
+	return nil
+}
+
+// validateOperationExamples validates the examples of the operation, and
+// returns the first error found.
+func validateOperationExamples(operation *openapi3.Operation) error {
+	if errs := operationExamplesErrors(operation); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// operationExamplesErrors validates the examples of the operation. The errors
+// are sectionError, one for each part of the operation with an invalid example.
+func operationExamplesErrors(operation *openapi3.Operation) []error {
+	var errs []error
+	for _, parameter := range operation.Parameters {
+		if parameter == nil || parameter.Value == nil {
+			continue
+		}
+		if err := validateParameterExamples(parameter.Value); err != nil {
+			errs = append(errs, &sectionError{section: parameterSection(parameter), err: err})
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		if err := validateContentExamples(operation.RequestBody.Value.Content, openapi3.VisitAsRequest()); err != nil {
+			errs = append(errs, &sectionError{section: "request body", err: err})
+		}
+	}
+
+	if operation.Responses == nil {
+		return errs
+	}
+	responses := operation.Responses.Map()
+	statusCodes := make([]string, 0, len(responses))
+	for statusCode := range responses {
+		statusCodes = append(statusCodes, statusCode)
+	}
+	sort.Strings(statusCodes)
+	for _, statusCode := range statusCodes {
+		response := responses[statusCode]
+		if response == nil || response.Value == nil {
+			continue
+		}
+		if err := validateContentExamples(response.Value.Content, openapi3.VisitAsResponse()); err != nil {
+			errs = append(errs, &sectionError{section: "response " + statusCode, err: err})
+			continue
+		}
+		if err := validateHeadersExamples(response.Value.Headers); err != nil {
+			errs = append(errs, &sectionError{section: "response " + statusCode, err: err})
+		}
+	}
+	return errs
+}
+
+func validateParameterExamples(parameter *openapi3.Parameter) error {
+	if parameter.Schema != nil {
+		return validateExampleValues(parameter.Schema, parameter.Example, parameter.Examples, openapi3.VisitAsRequest())
+	}
+	return validateContentExamples(parameter.Content, openapi3.VisitAsRequest())
+}
+
+func validateHeadersExamples(headers openapi3.Headers) error {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		header := headers[name]
+		if header == nil || header.Value == nil || header.Value.Schema == nil {
+			continue
+		}
+		if err := validateExampleValues(header.Value.Schema, header.Value.Example, header.Value.Examples, openapi3.VisitAsResponse()); err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateContentExamples(content openapi3.Content, opts ...openapi3.SchemaValidationOption) error {
+	contentTypes := make([]string, 0, len(content))
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	for _, contentType := range contentTypes {
+		mediaType := content[contentType]
+		if mediaType == nil {
+			continue
+		}
+		if err := validateExampleValues(mediaType.Schema, mediaType.Example, mediaType.Examples, opts...); err != nil {
+			return fmt.Errorf("content %s: %w", contentType, err)
+		}
+	}
+	return nil
+}
+
+func validateExampleValues(schema *openapi3.SchemaRef, example interface{}, examples openapi3.Examples, opts ...openapi3.SchemaValidationOption) error {
+	if schema == nil || schema.Value == nil {
+		return nil
+	}
+	opts = append(opts, openapi3.MultiErrors())
+
+	if example != nil {
+		if err := schema.Value.VisitJSON(example, opts...); err != nil {
+			return fmt.Errorf("invalid example: %w", err)
+		}
+	}
+
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		example := examples[name]
+		if example == nil || example.Value == nil {
+			continue
+		}
+		if err := schema.Value.VisitJSON(example.Value.Value, opts...); err != nil {
+			return fmt.Errorf("invalid example %s: %w", name, err)
+		}
+	}
+	return nil
+}
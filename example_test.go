@@ -0,0 +1,142 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type exampleUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age,omitempty" jsonschema:"minimum=0"`
+}
+
+func TestExamples(t *testing.T) {
+	t.Run("examples of contents and parameters", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			Querystring: ParameterValue{
+				"dryRun": {
+					Schema:  &Schema{Value: false},
+					Example: true,
+				},
+				"source": {
+					Schema: &Schema{Value: "", Example: "web"},
+				},
+			},
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: exampleUser{}, Example: exampleUser{Name: "Jane", Age: 30}},
+				},
+			},
+			Responses: map[int]ContentValue{
+				http.StatusCreated: {
+					Content: Content{
+						jsonType: {Value: exampleUser{}},
+					},
+					Examples: Examples{
+						"adult": {Summary: "an adult", Value: exampleUser{Name: "Jane", Age: 30}},
+						"child": {Description: "a child", Value: exampleUser{Name: "Tom", Age: 8}},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/examples.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("example and examples are mutually exclusive", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {
+						Value:    exampleUser{},
+						Example:  exampleUser{Name: "Jane"},
+						Examples: Examples{"tom": {Value: exampleUser{Name: "Tom"}}},
+					},
+				},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: POST /users: request body: example and examples are mutually exclusive", ErrRequestBody))
+	})
+
+	invalidExamplesTests := []struct {
+		name          string
+		definitions   Definitions
+		expectedError string
+	}{
+		{
+			name: "invalid response example",
+			definitions: Definitions{
+				Responses: map[int]ContentValue{
+					http.StatusOK: {
+						Content: Content{
+							jsonType: {Value: exampleUser{}},
+						},
+						Examples: Examples{
+							"negative age": {Value: exampleUser{Name: "Jane", Age: -1}},
+						},
+					},
+				},
+			},
+			expectedError: "route GET /users: response 200: content application/json: invalid example negative age: Error at \"/age\": number must be at least 0",
+		},
+		{
+			name: "invalid request body example",
+			definitions: Definitions{
+				RequestBody: &ContentValue{
+					Content: Content{
+						jsonType: {Value: exampleUser{}, Example: map[string]interface{}{"age": 1}},
+					},
+				},
+			},
+			expectedError: "route GET /users: request body: content application/json: invalid example: Error at \"/name\": property \"name\" is missing",
+		},
+		{
+			name: "invalid parameter example",
+			definitions: Definitions{
+				Querystring: ParameterValue{
+					"limit": {
+						Schema:  &Schema{Value: 0},
+						Example: "ten",
+					},
+				},
+			},
+			expectedError: "route GET /users: query parameter limit: invalid example: value must be an integer",
+		},
+	}
+
+	for _, test := range invalidExamplesTests {
+		t.Run(test.name, func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{})
+
+			_, err := router.AddRoute(http.MethodGet, "/users", okHandler, test.definitions)
+			require.NoError(t, err)
+
+			err = router.GenerateAndExposeOpenapi()
+			require.ErrorIs(t, err, ErrValidatingOAS)
+			require.ErrorContains(t, err, test.expectedError)
+		})
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"reflect"
 	"strings"
 
 	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/security"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/ghodss/yaml"
 )
@@ -36,12 +39,30 @@ const (
 // api router supported out of the box are:
 // - gorilla mux
 type Router[HandlerFunc, Route any] struct {
-	router                apirouter.Router[HandlerFunc, Route]
-	swaggerSchema         *openapi3.T
-	context               context.Context
-	jsonDocumentationPath string
-	yamlDocumentationPath string
-	pathPrefix            string
+	router                    apirouter.Router[HandlerFunc, Route]
+	swaggerSchema             *openapi3.T
+	context                   context.Context
+	jsonDocumentationPath     string
+	yamlDocumentationPath     string
+	pathPrefix                string
+	schemaComponents          bool
+	schemaNamer               SchemaNamer
+	schemaTypes               map[string]reflect.Type
+	typeMappings              TypeMappings
+	validateTags              bool
+	onUnsupportedValidateTag  UnsupportedValidateTagHandler
+	splitReadWriteSchemas     bool
+	openapi31                 bool
+	webhooks                  map[string]*openapi3.PathItem
+	defaultResponses          map[int]*openapi3.ResponseRef
+	securityAuthenticators    *security.Authenticators
+	tagGroups                 map[string]string
+	onUndeclaredTag           UndeclaredTagHandler
+	operationIDStrategy       OperationIDStrategy
+	operationIDs              map[string]string
+	allowRouteReplacement     bool
+	routes                    map[string]routeRegistration
+	aggregateValidationErrors bool
 }
 
 // Options to be passed to create the new router and swagger
@@ -54,15 +75,82 @@ type Options struct {
 	YAMLDocumentationPath string
 	// Add path prefix to add to every router path.
 	PathPrefix string
+	// UseSchemaComponents, if true, adds every named Go type used by AddRoute
+	// to the components schemas, and references it with $ref instead of
+	// repeating the whole schema.
+	UseSchemaComponents bool
+	// SchemaNamer is used to name the components schemas. Default to DefaultSchemaNamer.
+	SchemaNamer SchemaNamer
+	// TypeMappings set the schema of the Go types, used instead of reflecting
+	// them in the schemas generated by AddRoute (also when they are nested fields).
+	// Types implementing encoding.TextMarshaler, if not set, are mapped to string.
+	TypeMappings TypeMappings
+	// ValidateTags, if true, translates the go-playground/validator `validate` struct
+	// tags into schema constraints (required, min, max, len, oneof, formats and patterns).
+	ValidateTags bool
+	// OnUnsupportedValidateTag is called for each validate tag which can't be translated.
+	// If not set, AddRoute fails reporting all the unsupported tags.
+	OnUnsupportedValidateTag UnsupportedValidateTagHandler
+	// SplitReadWriteSchemas, if true, removes the readOnly properties from the
+	// schemas of the requests and the writeOnly properties from the schemas of
+	// the responses. The components schemas with these properties are referenced
+	// through their variants, named with the Input or Output suffix (e.g.
+	// UserInput). The original components schemas are kept, also if no route
+	// references them.
+	SplitReadWriteSchemas bool
+	// Openapi31, if true, generates an OpenAPI 3.1 document instead of a 3.0 one.
+	// Nullable schemas use the null type, pointer fields are nullable and the
+	// webhooks added with AddWebhook are exposed.
+	Openapi31 bool
+	// DefaultResponses are added, by status code, to the responses of every route
+	// which does not declare nor exclude them (see Definitions.ExcludeDefaultResponses).
+	// If UseSchemaComponents is set, they are added to the components responses,
+	// named by the status code (e.g. BadRequest), and referenced.
+	DefaultResponses map[int]ContentValue
+	// Security is the default security requirements of the routes, which can
+	// override it with the Definitions Security. The security schemes are
+	// added with AddSecurityScheme.
+	Security SecurityRequirements
+	// SecurityAuthenticators, if set, enables the enforcement of the security
+	// requirements of the routes added by AddRoute: the requests which do not
+	// satisfy them are rejected with 401 or 403 problem details. The api
+	// router must implement apirouter.SecurityRouter.
+	SecurityAuthenticators *security.Authenticators
+	// OnUndeclaredTag is called by GenerateAndExposeOpenapi for each tag used by
+	// the routes and not declared with AddTag (e.g. RejectUndeclaredTags).
+	// If not set, the undeclared tags are allowed.
+	OnUndeclaredTag UndeclaredTagHandler
+	// OperationIDStrategy sets the operation id of the routes added by AddRoute
+	// without the Definitions OperationID (e.g. MethodPathOperationID or
+	// HandlerNameOperationID). If not set, these operations have no id.
+	OperationIDStrategy OperationIDStrategy
+	// AllowRouteReplacement, if true, allows to register a route with the method
+	// and an equivalent path of an already registered one, replacing its
+	// operation in the openapi schema. The handler is added to the api router
+	// anyway, which chooses the one serving the requests. If not set, the
+	// duplicate routes are rejected with a RouteError caused by a
+	// DuplicateRouteError.
+	AllowRouteReplacement bool
+	// AggregateValidationErrors, if true, makes GenerateAndExposeOpenapi report
+	// all the errors found validating the openapi document, instead of the first
+	// one, in ValidationErrors. There is an error for each invalid component and
+	// a RouteError for each invalid section of the operations: each parameter,
+	// the request body and each response.
+	AggregateValidationErrors bool
 }
 
-// NewRouter generate new router with openapi. Default to OpenAPI 3.0.0
+// NewRouter generate new router with openapi. Default to OpenAPI 3.0.0, or 3.1.0 if
+// Openapi31 option is set.
 func NewRouter[HandlerFunc, Route any](router apirouter.Router[HandlerFunc, Route], options Options) (*Router[HandlerFunc, Route], error) {
 	openapi, err := generateNewValidOpenapi(options.Openapi)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrValidatingOAS, err)
 	}
 
+	if options.Security != nil {
+		openapi.Security = options.Security.openapi()
+	}
+
 	var ctx = options.Context
 	if options.Context == nil {
 		ctx = context.Background()
@@ -84,29 +172,78 @@ func NewRouter[HandlerFunc, Route any](router apirouter.Router[HandlerFunc, Rout
 		jsonDocumentationPath = options.JSONDocumentationPath
 	}
 
-	return &Router[HandlerFunc, Route]{
-		router:                router,
-		swaggerSchema:         openapi,
-		context:               ctx,
-		yamlDocumentationPath: yamlDocumentationPath,
-		jsonDocumentationPath: jsonDocumentationPath,
-		pathPrefix:            options.PathPrefix,
-	}, nil
+	r := &Router[HandlerFunc, Route]{
+		router:                    router,
+		swaggerSchema:             openapi,
+		context:                   ctx,
+		yamlDocumentationPath:     yamlDocumentationPath,
+		jsonDocumentationPath:     jsonDocumentationPath,
+		pathPrefix:                options.PathPrefix,
+		schemaComponents:          options.UseSchemaComponents,
+		schemaNamer:               options.SchemaNamer,
+		schemaTypes:               map[string]reflect.Type{},
+		typeMappings:              options.TypeMappings,
+		validateTags:              options.ValidateTags,
+		onUnsupportedValidateTag:  options.OnUnsupportedValidateTag,
+		splitReadWriteSchemas:     options.SplitReadWriteSchemas,
+		openapi31:                 options.Openapi31,
+		webhooks:                  map[string]*openapi3.PathItem{},
+		securityAuthenticators:    options.SecurityAuthenticators,
+		tagGroups:                 map[string]string{},
+		onUndeclaredTag:           options.OnUndeclaredTag,
+		operationIDStrategy:       options.OperationIDStrategy,
+		operationIDs:              map[string]string{},
+		allowRouteReplacement:     options.AllowRouteReplacement,
+		routes:                    map[string]routeRegistration{},
+		aggregateValidationErrors: options.AggregateValidationErrors,
+	}
+	if r.defaultResponses, err = r.resolveDefaultResponses(nil, options.DefaultResponses); err != nil {
+		return nil, fmt.Errorf("%w: default responses: %s", ErrResponses, err)
+	}
+	return r, nil
 }
 
 type SubRouterOptions struct {
+	// PathPrefix is added to the path prefix of the router, for the routes of
+	// the sub router and of its sub routers.
 	PathPrefix string
+	// DefaultResponses are added to the router DefaultResponses, overriding the
+	// ones with the same status code, for the routes of the sub router.
+	DefaultResponses map[int]ContentValue
 }
 
 func (r Router[HandlerFunc, Route]) SubRouter(router apirouter.Router[HandlerFunc, Route], opts SubRouterOptions) (*Router[HandlerFunc, Route], error) {
-	return &Router[HandlerFunc, Route]{
-		router:                router,
-		swaggerSchema:         r.swaggerSchema,
-		context:               r.context,
-		jsonDocumentationPath: r.jsonDocumentationPath,
-		yamlDocumentationPath: r.yamlDocumentationPath,
-		pathPrefix:            opts.PathPrefix,
-	}, nil
+	subRouter := &Router[HandlerFunc, Route]{
+		router:                    router,
+		swaggerSchema:             r.swaggerSchema,
+		context:                   r.context,
+		jsonDocumentationPath:     r.jsonDocumentationPath,
+		yamlDocumentationPath:     r.yamlDocumentationPath,
+		pathPrefix:                path.Join(r.pathPrefix, opts.PathPrefix),
+		schemaComponents:          r.schemaComponents,
+		schemaNamer:               r.schemaNamer,
+		schemaTypes:               r.schemaTypes,
+		typeMappings:              r.typeMappings,
+		validateTags:              r.validateTags,
+		onUnsupportedValidateTag:  r.onUnsupportedValidateTag,
+		splitReadWriteSchemas:     r.splitReadWriteSchemas,
+		openapi31:                 r.openapi31,
+		webhooks:                  r.webhooks,
+		securityAuthenticators:    r.securityAuthenticators,
+		tagGroups:                 r.tagGroups,
+		onUndeclaredTag:           r.onUndeclaredTag,
+		operationIDStrategy:       r.operationIDStrategy,
+		operationIDs:              r.operationIDs,
+		allowRouteReplacement:     r.allowRouteReplacement,
+		routes:                    r.routes,
+		aggregateValidationErrors: r.aggregateValidationErrors,
+	}
+	defaultResponses, err := subRouter.resolveDefaultResponses(r.defaultResponses, opts.DefaultResponses)
+	if err != nil {
+		return nil, fmt.Errorf("%w: default responses: %s", ErrResponses, err)
+	}
+	subRouter.defaultResponses = defaultResponses
+	return subRouter, nil
 }
 
 func generateNewValidOpenapi(openapi *openapi3.T) (*openapi3.T, error) {
@@ -134,13 +271,18 @@ func generateNewValidOpenapi(openapi *openapi3.T) (*openapi3.T, error) {
 }
 
 // GenerateAndExposeOpenapi creates a /documentation/json route on router and
-// expose the generated swagger
+// expose the generated swagger. It fails if the openapi is not valid, or if an
+// example does not match its schema.
 func (r Router[_, _]) GenerateAndExposeOpenapi() error {
-	if err := r.swaggerSchema.Validate(r.context); err != nil {
-		return fmt.Errorf("%w: %s", ErrValidatingOAS, err)
+	r.setTagGroups()
+	if err := r.validateOpenapi(); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidatingOAS, err)
 	}
 
 	jsonSwagger, err := r.swaggerSchema.MarshalJSON()
+	if err == nil && r.openapi31 {
+		jsonSwagger, err = r.toOpenapi31(jsonSwagger)
+	}
 	if err != nil {
 		return fmt.Errorf("%w json marshal: %s", ErrGenerateOAS, err)
 	}
@@ -155,6 +297,30 @@ func (r Router[_, _]) GenerateAndExposeOpenapi() error {
 	return nil
 }
 
+func (r Router[_, _]) validateOpenapi() error {
+	// The examples are validated apart, to report the route which contains the invalid one.
+	opts := append(r.validationOptions(), openapi3.DisableExamplesValidation())
+	if r.aggregateValidationErrors {
+		if errs := r.collectValidationErrors(opts...); len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+	if err := r.swaggerSchema.Validate(r.context, opts...); err != nil {
+		return err
+	}
+	if err := r.validateWebhooks(opts...); err != nil {
+		return err
+	}
+	if err := r.checkSecurityRequirements(r.swaggerSchema.Security); err != nil {
+		return fmt.Errorf("default security: %w", err)
+	}
+	if err := r.checkUndeclaredTags(); err != nil {
+		return err
+	}
+	return r.validateExamples()
+}
+
 func isValidDocumentationPath(path string) error {
 	if !strings.HasPrefix(path, "/") {
 		return fmt.Errorf("invalid path %s. Path should start with '/'", path)
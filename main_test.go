@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -48,6 +49,11 @@ func TestNewRouter(t *testing.T) {
 			swaggerSchema:         openapi,
 			jsonDocumentationPath: DefaultJSONDocumentationPath,
 			yamlDocumentationPath: DefaultYAMLDocumentationPath,
+			schemaTypes:           map[string]reflect.Type{},
+			webhooks:              map[string]*openapi3.PathItem{},
+			tagGroups:             map[string]string{},
+			operationIDs:          map[string]string{},
+			routes:                map[string]routeRegistration{},
 		}, r)
 	})
 
@@ -66,6 +72,11 @@ func TestNewRouter(t *testing.T) {
 			swaggerSchema:         openapi,
 			jsonDocumentationPath: DefaultJSONDocumentationPath,
 			yamlDocumentationPath: DefaultYAMLDocumentationPath,
+			schemaTypes:           map[string]reflect.Type{},
+			webhooks:              map[string]*openapi3.PathItem{},
+			tagGroups:             map[string]string{},
+			operationIDs:          map[string]string{},
+			routes:                map[string]routeRegistration{},
 		}, r)
 	})
 
@@ -86,6 +97,11 @@ func TestNewRouter(t *testing.T) {
 			swaggerSchema:         openapi,
 			jsonDocumentationPath: "/json/path",
 			yamlDocumentationPath: "/yaml/path",
+			schemaTypes:           map[string]reflect.Type{},
+			webhooks:              map[string]*openapi3.PathItem{},
+			tagGroups:             map[string]string{},
+			operationIDs:          map[string]string{},
+			routes:                map[string]routeRegistration{},
 		}, r)
 	})
 
@@ -431,6 +447,74 @@ func TestGenerateAndExposeSwagger(t *testing.T) {
 		require.NoError(t, err)
 		require.JSONEq(t, string(actual), body, body)
 	})
+
+	t.Run("ok - nested sub routers with path prefixes", func(t *testing.T) {
+		mRouter := mux.NewRouter()
+
+		router, err := NewRouter(gorilla.NewRouter(mRouter), Options{
+			Openapi: &openapi3.T{
+				Info: &openapi3.Info{
+					Title:   "test openapi title",
+					Version: "test openapi version",
+				},
+			},
+			PathPrefix: "/api",
+		})
+		require.NoError(t, err)
+
+		tenantRouter, err := router.SubRouter(gorilla.NewRouter(mRouter.NewRoute().Subrouter()), SubRouterOptions{
+			PathPrefix: "/tenants/{tenantId}",
+		})
+		require.NoError(t, err)
+
+		projectRouter, err := tenantRouter.SubRouter(gorilla.NewRouter(mRouter.NewRoute().Subrouter()), SubRouterOptions{
+			PathPrefix: "/projects/{projectId}",
+		})
+		require.NoError(t, err)
+
+		_, err = projectRouter.AddRoute(http.MethodGet, "/users/{userId}", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(mux.Vars(req)["tenantId"]))
+		}, Definitions{
+			PathParams: ParameterValue{
+				"projectId": {
+					Schema: &Schema{Value: 0},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = projectRouter.AddRoute(http.MethodGet, "/groups", func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}, Definitions{
+			PathParams: ParameterValue{
+				"groupId": {},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /api/tenants/{tenantId}/projects/{projectId}/groups: path parameter groupId: parameter is not in the path /api/tenants/{tenantId}/projects/{projectId}/groups", ErrPathParams))
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		mRouter.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		actual, err := os.ReadFile("testdata/nested-subrouters.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(actual), body, body)
+
+		t.Run("test request /api/tenants/acme/projects/1/users/2", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/tenants/acme/projects/1/users/2", nil)
+			mRouter.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+			require.Equal(t, "acme", readBody(t, w.Result().Body))
+		})
+	})
 }
 
 func readBody(t *testing.T, requestBody io.ReadCloser) string {
@@ -0,0 +1,289 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/jsonschema"
+)
+
+const openapi31Version = "3.1.0"
+
+// openapi31SchemaKeywords are the json schema keywords valid in openapi 3.1
+// and not in 3.0, which are kept as extensions by the openapi 3.0 model.
+var openapi31SchemaKeywords = []string{"const", "examples"}
+
+// AddWebhook adds to the openapi schema the webhook with the given name, which
+// receives requests with the given method. Its operation is inferred by the
+// definitions, as in AddRoute. Webhooks are supported only if Openapi31 option is set.
+func (r Router[_, _]) AddWebhook(name string, method string, schema Definitions) error {
+	if !r.openapi31 {
+		return fmt.Errorf("webhooks are supported only with openapi 3.1")
+	}
+	if name == "" {
+		return fmt.Errorf("webhook name is required")
+	}
+
+	staged, commitComponents := r.stageComponents()
+	operation, err := staged.newOperation("", schema, nil)
+	if err != nil {
+		return setRoute(err, method, name)
+	}
+	if err := operation.Validate(r.context, append(r.validationOptions(), openapi3.DisableExamplesValidation())...); err != nil {
+		return setRoute(newRouteError(nil, "operation", err), method, name)
+	}
+
+	if err := r.registerOperationID(operation.OperationID, fmt.Sprintf("webhook %s %s", method, name)); err != nil {
+		return setRoute(newRouteError(nil, "operation id", err), method, name)
+	}
+	commitComponents()
+
+	pathItem, ok := r.webhooks[name]
+	if !ok {
+		pathItem = &openapi3.PathItem{}
+		r.webhooks[name] = pathItem
+	}
+	pathItem.SetOperation(method, operation.Operation)
+	return nil
+}
+
+// validationOptions returns the options to validate the openapi schema.
+func (r Router[_, _]) validationOptions() []openapi3.ValidationOption {
+	if !r.openapi31 {
+		return nil
+	}
+	return []openapi3.ValidationOption{openapi3.AllowExtraSiblingFields(openapi31SchemaKeywords...)}
+}
+
+func (r Router[_, _]) validateWebhooks(opts ...openapi3.ValidationOption) error {
+	names := make([]string, 0, len(r.webhooks))
+	for name := range r.webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := r.webhooks[name].Validate(r.context, opts...); err != nil {
+			return fmt.Errorf("invalid webhook %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// toOpenapi31 converts the marshalled openapi 3.0 document into an openapi 3.1
+// one, adding the webhooks.
+func (r Router[_, _]) toOpenapi31(data []byte) ([]byte, error) {
+	value, err := unmarshalJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+	document, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("openapi document is not an object")
+	}
+
+	if version, _ := document["openapi"].(string); !strings.HasPrefix(version, "3.1.") {
+		document["openapi"] = openapi31Version
+	}
+
+	if len(r.webhooks) > 0 {
+		webhooksData, err := json.Marshal(r.webhooks)
+		if err != nil {
+			return nil, err
+		}
+		webhooks, err := unmarshalJSONValue(webhooksData)
+		if err != nil {
+			return nil, err
+		}
+		document["webhooks"] = webhooks
+	}
+
+	convertDocumentSchemas(document)
+	return json.Marshal(document)
+}
+
+// convertDocumentSchemas converts to openapi 3.1 all the schemas of the openapi
+// document: the ones of the components, and the ones of the operations of the
+// paths and of the webhooks. Only the fields of the openapi objects which
+// contain schemas are walked, since the examples, the defaults and the
+// extensions contain user values.
+func convertDocumentSchemas(document map[string]any) {
+	forEachValue(document["paths"], convertPathItem)
+	forEachValue(document["webhooks"], convertPathItem)
+
+	components, _ := document["components"].(map[string]any)
+	forEachValue(components["schemas"], convertSchema)
+	forEachValue(components["parameters"], convertParameter)
+	forEachValue(components["headers"], convertParameter)
+	forEachValue(components["requestBodies"], convertRequestBody)
+	forEachValue(components["responses"], convertResponse)
+	forEachValue(components["callbacks"], convertCallback)
+	forEachValue(components["pathItems"], convertPathItem)
+}
+
+// forEachValue calls convert with each value of the object, or each item of
+// the array.
+func forEachValue(value any, convert func(any)) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, item := range v {
+			convert(item)
+		}
+	case []any:
+		for _, item := range v {
+			convert(item)
+		}
+	}
+}
+
+func convertPathItem(value any) {
+	pathItem, _ := value.(map[string]any)
+	for key, item := range pathItem {
+		switch key {
+		case "parameters":
+			forEachValue(item, convertParameter)
+		case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+			convertOperation(item)
+		}
+	}
+}
+
+func convertOperation(value any) {
+	operation, _ := value.(map[string]any)
+	forEachValue(operation["parameters"], convertParameter)
+	convertRequestBody(operation["requestBody"])
+	forEachValue(operation["responses"], convertResponse)
+	forEachValue(operation["callbacks"], convertCallback)
+}
+
+func convertCallback(value any) {
+	forEachValue(value, convertPathItem)
+}
+
+// convertParameter converts the schemas of the parameter, or of the header.
+func convertParameter(value any) {
+	parameter, _ := value.(map[string]any)
+	convertSchema(parameter["schema"])
+	forEachValue(parameter["content"], convertMediaType)
+}
+
+func convertRequestBody(value any) {
+	requestBody, _ := value.(map[string]any)
+	forEachValue(requestBody["content"], convertMediaType)
+}
+
+func convertResponse(value any) {
+	response, _ := value.(map[string]any)
+	forEachValue(response["headers"], convertParameter)
+	forEachValue(response["content"], convertMediaType)
+}
+
+func convertMediaType(value any) {
+	mediaType, _ := value.(map[string]any)
+	convertSchema(mediaType["schema"])
+	forEachValue(mediaType["encoding"], func(encoding any) {
+		encodingObject, _ := encoding.(map[string]any)
+		forEachValue(encodingObject["headers"], convertParameter)
+	})
+}
+
+// convertSchema converts the openapi 3.0 schema and its sub schemas to openapi 3.1:
+// nullable schemas include the null type, exclusive bounds are numbers and the
+// example is moved into the examples.
+func convertSchema(value any) {
+	schema, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+
+	for _, key := range []string{"properties", "patternProperties"} {
+		if properties, ok := schema[key].(map[string]any); ok {
+			for _, property := range properties {
+				convertSchema(property)
+			}
+		}
+	}
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if schemas, ok := schema[key].([]any); ok {
+			for _, s := range schemas {
+				convertSchema(s)
+			}
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not"} {
+		convertSchema(schema[key])
+	}
+
+	convertExclusiveBound(schema, "exclusiveMinimum", "minimum")
+	convertExclusiveBound(schema, "exclusiveMaximum", "maximum")
+
+	if example, ok := schema["example"]; ok {
+		if _, ok := schema["examples"]; !ok {
+			schema["examples"] = []any{example}
+		}
+		delete(schema, "example")
+	}
+
+	if nullable, ok := schema["nullable"].(bool); ok {
+		delete(schema, "nullable")
+		if nullable {
+			setNullType(schema)
+		}
+	}
+}
+
+func convertExclusiveBound(schema map[string]any, exclusiveKey, boundKey string) {
+	exclusive, ok := schema[exclusiveKey].(bool)
+	if !ok {
+		return
+	}
+	delete(schema, exclusiveKey)
+	if bound, ok := schema[boundKey]; ok && exclusive {
+		schema[exclusiveKey] = bound
+		delete(schema, boundKey)
+	}
+}
+
+// setNullType makes the schema accept the null value, as the openapi 3.0
+// nullable keyword does.
+func setNullType(schema map[string]any) {
+	nullSchema := map[string]any{"type": "null"}
+	if schemaType, ok := schema["type"].(string); ok {
+		schema["type"] = []any{schemaType, "null"}
+		if enum, ok := schema["enum"].([]any); ok {
+			schema["enum"] = append(enum, nil)
+		}
+		return
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		schema["oneOf"] = append(oneOf, nullSchema)
+		return
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		schema["anyOf"] = append(anyOf, nullSchema)
+		return
+	}
+	if allOf, ok := schema["allOf"].([]any); ok {
+		var nonNullSchema any = map[string]any{"allOf": allOf}
+		if len(allOf) == 1 {
+			nonNullSchema = allOf[0]
+		}
+		delete(schema, "allOf")
+		schema["anyOf"] = []any{nonNullSchema, nullSchema}
+	}
+}
+
+// setNullablePointers sets as nullable the schemas of the struct fields with
+// pointer type, which are marshalled as null if not set.
+func setNullablePointers(schema *jsonschema.Schema, definitions jsonschema.Definitions, t reflect.Type) error {
+	return walkStructFields(schema, t, definitions, func(_ *jsonschema.Schema, _ string, property *jsonschema.Schema, field reflect.StructField) error {
+		if field.Type.Kind() == reflect.Ptr && property != jsonschema.TrueSchema && property != jsonschema.FalseSchema {
+			setExtra(property, "nullable", true)
+		}
+		return nil
+	})
+}
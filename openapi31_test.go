@@ -0,0 +1,229 @@
+package swagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type openapi31Address struct {
+	Street string `json:"street"`
+}
+
+type openapi31User struct {
+	Name     string            `json:"name" jsonschema:"example=Jane"`
+	Kind     string            `json:"kind" jsonschema_extras:"const=user"`
+	Nickname *string           `json:"nickname,omitempty"`
+	Role     *string           `json:"role,omitempty" jsonschema:"enum=admin,enum=user"`
+	Age      int               `json:"age" validate:"gt=0"`
+	Email    string            `json:"email" jsonschema:"nullable"`
+	Address  *openapi31Address `json:"address,omitempty"`
+}
+
+type openapi31Event struct {
+	ID   string         `json:"id"`
+	User *openapi31User `json:"user"`
+}
+
+func TestOpenapi31(t *testing.T) {
+	tests := []struct {
+		name                string
+		useSchemaComponents bool
+		fixturesPath        string
+	}{
+		{
+			name:         "inline schemas",
+			fixturesPath: "testdata/openapi31.json",
+		},
+		{
+			name:                "schema components",
+			useSchemaComponents: true,
+			fixturesPath:        "testdata/openapi31-components.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			router := newTestRouter(t, r, Options{
+				Openapi31:           true,
+				UseSchemaComponents: test.useSchemaComponents,
+				ValidateTags:        true,
+			})
+
+			_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+				RequestBody: &ContentValue{
+					Content: Content{
+						jsonType: {Value: openapi31User{}},
+					},
+				},
+				Responses: map[int]ContentValue{
+					http.StatusCreated: {
+						Content: Content{
+							jsonType: {Value: &openapi31User{}},
+						},
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			err = router.AddWebhook("userCreated", http.MethodPost, Definitions{
+				RequestBody: &ContentValue{
+					Content: Content{
+						jsonType: {Value: openapi31Event{}},
+					},
+				},
+				Responses: map[int]ContentValue{
+					http.StatusOK: {Description: "event received"},
+				},
+			})
+			require.NoError(t, err)
+
+			err = router.GenerateAndExposeOpenapi()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			body := readBody(t, w.Result().Body)
+			expected, err := os.ReadFile(test.fixturesPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, DefaultYAMLDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+			require.Contains(t, readBody(t, w.Result().Body), "openapi: 3.1.0")
+		})
+	}
+
+	t.Run("default responses", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{
+			Openapi31:        true,
+			ValidateTags:     true,
+			DefaultResponses: ProblemResponses(nil, http.StatusInternalServerError),
+		})
+		require.NoError(t, router.AddResponseComponent("default", ContentValue{
+			Description: "the user",
+			Content: Content{
+				jsonType: {Value: openapi31User{}},
+			},
+		}))
+
+		_, err := router.AddRoute(http.MethodGet, "/users/{userId}", okHandler, Definitions{
+			Responses: map[int]ContentValue{
+				http.StatusOK: {Ref: "default"},
+			},
+			RangeResponses: map[string]ContentValue{
+				"4XX": ProblemResponse("client error", nil),
+			},
+			DefaultResponse: &ContentValue{
+				Content: Content{
+					jsonType: {Value: &openapi31User{}},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/openapi31-default-responses.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("webhooks require openapi 3.1", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		err := router.AddWebhook("userCreated", http.MethodPost, Definitions{})
+		require.EqualError(t, err, "webhooks are supported only with openapi 3.1")
+	})
+
+	t.Run("webhook name is required", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			Openapi31: true,
+		})
+
+		err := router.AddWebhook("", http.MethodPost, Definitions{})
+		require.EqualError(t, err, "webhook name is required")
+	})
+}
+
+func TestConvertSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   map[string]any
+		expected map[string]any
+	}{
+		{
+			name:     "nullable type",
+			schema:   map[string]any{"type": "string", "nullable": true},
+			expected: map[string]any{"type": []any{"string", "null"}},
+		},
+		{
+			name:     "nullable enum",
+			schema:   map[string]any{"type": "string", "enum": []any{"a"}, "nullable": true},
+			expected: map[string]any{"type": []any{"string", "null"}, "enum": []any{"a", nil}},
+		},
+		{
+			name:   "nullable reference",
+			schema: map[string]any{"allOf": []any{map[string]any{"$ref": "#/components/schemas/User"}}, "nullable": true},
+			expected: map[string]any{"anyOf": []any{
+				map[string]any{"$ref": "#/components/schemas/User"},
+				map[string]any{"type": "null"},
+			}},
+		},
+		{
+			name:     "not nullable",
+			schema:   map[string]any{"type": "string", "nullable": false},
+			expected: map[string]any{"type": "string"},
+		},
+		{
+			name:     "exclusive bounds",
+			schema:   map[string]any{"type": "integer", "minimum": 1, "exclusiveMinimum": true, "maximum": 10, "exclusiveMaximum": false},
+			expected: map[string]any{"type": "integer", "exclusiveMinimum": 1, "maximum": 10},
+		},
+		{
+			name:     "example",
+			schema:   map[string]any{"type": "string", "example": "foo"},
+			expected: map[string]any{"type": "string", "examples": []any{"foo"}},
+		},
+		{
+			name: "sub schemas",
+			schema: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tags": map[string]any{"type": "array", "items": map[string]any{"type": "string", "nullable": true}},
+				},
+			},
+			expected: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"tags": map[string]any{"type": "array", "items": map[string]any{"type": []any{"string", "null"}}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			convertSchema(test.schema)
+			require.Equal(t, test.expected, test.schema)
+		})
+	}
+}
@@ -42,7 +42,7 @@ func (o *Operation) addSecurityRequirements(securityRequirements SecurityRequire
 	if securityRequirements != nil && o.Security == nil {
 		o.Security = openapi3.NewSecurityRequirements()
 	}
-	for _, securityRequirement := range securityRequirements {
-		o.Security.With(openapi3.SecurityRequirement(securityRequirement))
+	for _, securityRequirement := range securityRequirements.openapi() {
+		o.Security.With(securityRequirement)
 	}
 }
@@ -0,0 +1,78 @@
+package swagger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrOperationID is thrown if the operation id is already used by another operation.
+var ErrOperationID = errors.New("errors checking operation id")
+
+// anonymousFuncName matches the names given by the compiler to the anonymous functions.
+var anonymousFuncName = regexp.MustCompile(`^func\d+$`)
+
+// OperationIDStrategy returns the operation id of the routes added with
+// AddRoute without the Definitions OperationID, from their method, their
+// openapi path (with the router prefix) and their handler. If it returns an
+// empty string, the operation has no id.
+type OperationIDStrategy func(method, path string, handler interface{}) string
+
+// MethodPathOperationID is the OperationIDStrategy which joins in camel case
+// the method and the words of the path segments, e.g. getUsersUserId for
+// GET /users/{userId}.
+func MethodPathOperationID(method, path string, _ interface{}) string {
+	var operationID strings.Builder
+	operationID.WriteString(strings.ToLower(method))
+	words := strings.FieldsFunc(path, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	for _, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		operationID.WriteRune(unicode.ToUpper(first))
+		operationID.WriteString(word[size:])
+	}
+	return operationID.String()
+}
+
+// HandlerNameOperationID is the OperationIDStrategy which uses the name of the
+// handler function, or method. The anonymous functions have no name, so their
+// operations have no id.
+func HandlerNameOperationID(_, _ string, handler interface{}) string {
+	value := reflect.ValueOf(handler)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return ""
+	}
+	fn := runtime.FuncForPC(value.Pointer())
+	if fn == nil {
+		return ""
+	}
+	// The type arguments of the generic functions and types are named [...],
+	// which may contain dots.
+	name := strings.ReplaceAll(fn.Name(), "[...]", "")
+	name = strings.TrimSuffix(name, "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	if anonymousFuncName.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// registerOperationID registers the operation id used by the operation at the
+// given location, failing if it is already used by another operation of the
+// router, of its sub routers or of the webhooks.
+func (r Router[_, _]) registerOperationID(operationID string, location string) error {
+	if operationID == "" {
+		return nil
+	}
+	if usedBy, ok := r.operationIDs[operationID]; ok {
+		return fmt.Errorf("%w: operation id %s of %s is already used by %s", ErrOperationID, operationID, location, usedBy)
+	}
+	r.operationIDs[operationID] = location
+	return nil
+}
@@ -0,0 +1,222 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/support/gorilla"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func listUsers(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+type usersHandler struct{}
+
+func (usersHandler) getUser(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func listItems[T any](w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+type itemsHandler[T any] struct{}
+
+func (itemsHandler[T]) getItem(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestOperationIDStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy OperationIDStrategy
+		method   string
+		path     string
+		handler  interface{}
+		expected string
+	}{
+		{
+			name:     "method and path",
+			strategy: MethodPathOperationID,
+			method:   http.MethodGet,
+			path:     "/users/{userId}/posts",
+			expected: "getUsersUserIdPosts",
+		},
+		{
+			name:     "method and path with separators in segments",
+			strategy: MethodPathOperationID,
+			method:   http.MethodPost,
+			path:     "/v1/user-groups/{group_id}",
+			expected: "postV1UserGroupsGroupId",
+		},
+		{
+			name:     "method and path with multi-byte first letters",
+			strategy: MethodPathOperationID,
+			method:   http.MethodGet,
+			path:     "/élèves/{ñame}",
+			expected: "getÉlèvesÑame",
+		},
+		{
+			name:     "method and root path",
+			strategy: MethodPathOperationID,
+			method:   http.MethodGet,
+			path:     "/",
+			expected: "get",
+		},
+		{
+			name:     "handler function name",
+			strategy: HandlerNameOperationID,
+			handler:  listUsers,
+			expected: "listUsers",
+		},
+		{
+			name:     "handler method name",
+			strategy: HandlerNameOperationID,
+			handler:  usersHandler{}.getUser,
+			expected: "getUser",
+		},
+		{
+			name:     "generic handler function name",
+			strategy: HandlerNameOperationID,
+			handler:  listItems[exampleUser],
+			expected: "listItems",
+		},
+		{
+			name:     "generic handler method name",
+			strategy: HandlerNameOperationID,
+			handler:  itemsHandler[exampleUser]{}.getItem,
+			expected: "getItem",
+		},
+		{
+			name:     "handler of named type",
+			strategy: HandlerNameOperationID,
+			handler:  gorilla.HandlerFunc(listUsers),
+			expected: "listUsers",
+		},
+		{
+			name:     "anonymous handler",
+			strategy: HandlerNameOperationID,
+			handler:  func(w http.ResponseWriter, req *http.Request) {},
+			expected: "",
+		},
+		{
+			name:     "nil handler",
+			strategy: HandlerNameOperationID,
+			handler:  nil,
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.strategy(test.method, test.path, test.handler))
+		})
+	}
+}
+
+func TestOperationID(t *testing.T) {
+	t.Run("explicit and generated operation ids", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{
+			OperationIDStrategy: MethodPathOperationID,
+		})
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			OperationID: "listUsers",
+		})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{})
+		require.NoError(t, err)
+
+		subRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{PathPrefix: "/v1"})
+		require.NoError(t, err)
+		_, err = subRouter.AddRoute(http.MethodGet, "/users/{userId}", okHandler, Definitions{})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/operation-ids.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("without strategy the operations have no id", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+		require.NoError(t, err)
+
+		operation := router.swaggerSchema.Paths.Find("/users").Get
+		require.Empty(t, operation.OperationID)
+	})
+
+	t.Run("duplicate operation ids", func(t *testing.T) {
+		t.Run("in the same router", func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{})
+			_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{OperationID: "listUsers"})
+			require.NoError(t, err)
+
+			_, err = router.AddRoute(http.MethodGet, "/users/{userId}", okHandler, Definitions{OperationID: "listUsers"})
+			require.EqualError(t, err, fmt.Sprintf("GET /users/{userId}: operation id: %s: operation id listUsers of GET /users/{userId} is already used by GET /users", ErrOperationID))
+			require.ErrorIs(t, err, ErrOperationID)
+			require.Nil(t, router.swaggerSchema.Paths.Find("/users/{userId}"))
+		})
+
+		t.Run("generated by the strategy", func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{
+				OperationIDStrategy: func(method, path string, handler interface{}) string {
+					return "sameId"
+				},
+			})
+			_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+			require.NoError(t, err)
+
+			_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{})
+			require.EqualError(t, err, fmt.Sprintf("POST /users: operation id: %s: operation id sameId of POST /users is already used by GET /users", ErrOperationID))
+		})
+
+		t.Run("across sub routers", func(t *testing.T) {
+			r := mux.NewRouter()
+			router := newTestRouter(t, r, Options{})
+			subRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{PathPrefix: "/v1"})
+			require.NoError(t, err)
+			otherSubRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{PathPrefix: "/v2"})
+			require.NoError(t, err)
+
+			_, err = subRouter.AddRoute(http.MethodGet, "/users", okHandler, Definitions{OperationID: "listUsers"})
+			require.NoError(t, err)
+
+			_, err = otherSubRouter.AddRoute(http.MethodGet, "/users", okHandler, Definitions{OperationID: "listUsers"})
+			require.EqualError(t, err, fmt.Sprintf("GET /v2/users: operation id: %s: operation id listUsers of GET /v2/users is already used by GET /v1/users", ErrOperationID))
+
+			_, err = router.AddRawRoute(http.MethodGet, "/users", okHandler, Operation{&openapi3.Operation{
+				OperationID: "listUsers",
+				Responses:   openapi3.NewResponses(),
+			}})
+			require.EqualError(t, err, fmt.Sprintf("GET /users: operation id: %s: operation id listUsers of GET /users is already used by GET /v1/users", ErrOperationID))
+		})
+
+		t.Run("with webhooks", func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{Openapi31: true})
+			_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{OperationID: "createUser"})
+			require.NoError(t, err)
+
+			err = router.AddWebhook("userCreated", http.MethodPost, Definitions{OperationID: "createUser"})
+			require.EqualError(t, err, fmt.Sprintf("POST userCreated: operation id: %s: operation id createUser of webhook POST userCreated is already used by POST /users", ErrOperationID))
+		})
+	})
+}
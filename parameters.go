@@ -0,0 +1,175 @@
+package swagger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// parameterTags are the struct tags which set the location of the parameters
+// defined by the fields of the Definitions Parameters struct.
+var parameterTags = []string{pathParamsType, queryParamType, headerParamType, cookieParamType}
+
+// structParameter is a parameter defined by a struct field.
+type structParameter struct {
+	field reflect.StructField
+	in    string
+	name  string
+}
+
+// resolveParametersStruct returns the parameters defined by the fields of the
+// struct tagged with query, header, path or cookie. The schema of each parameter
+// is reflected from the field, with its jsonschema and validate tags. The
+// description and the required keyword of the field are set in the parameter.
+func (r Router[_, _]) resolveParametersStruct(v interface{}) (openapi3.Parameters, error) {
+	if v == nil {
+		return nil, nil
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("parameters must be a struct, got %T", v)
+	}
+
+	params, err := getStructParameters(t)
+	if err != nil || len(params) == 0 {
+		return nil, err
+	}
+
+	// The fields are reflected in an anonymous struct, so the schema is never
+	// added to the components, and they are named as the Go field. The fields
+	// with the name of a previous one are suffixed with a number, which makes
+	// their name different from the ones of all the fields.
+	fields := make([]reflect.StructField, 0, len(params))
+	fieldNames := make([]string, 0, len(params))
+	usedNames := map[string]bool{}
+	for _, param := range params {
+		usedNames[param.field.Name] = true
+	}
+	isDuplicate := map[string]bool{}
+	for _, param := range params {
+		name := param.field.Name
+		if isDuplicate[name] {
+			for i := 2; usedNames[name]; i++ {
+				name = param.field.Name + strconv.Itoa(i)
+			}
+			usedNames[name] = true
+		}
+		isDuplicate[param.field.Name] = true
+		fieldNames = append(fieldNames, name)
+		fields = append(fields, reflect.StructField{
+			Name: name,
+			Type: param.field.Type,
+			Tag:  parameterFieldTag(param.field, name),
+		})
+	}
+	value := reflect.New(reflect.StructOf(fields)).Elem().Interface()
+	schema, err := r.getSchemaFromInterface(value, false)
+	if err != nil {
+		return nil, err
+	}
+
+	required := map[string]bool{}
+	for _, name := range schema.Value.Required {
+		required[name] = true
+	}
+
+	parameters := make(openapi3.Parameters, 0, len(params))
+	for i, param := range params {
+		property := schema.Value.Properties[fieldNames[i]]
+		if property == nil {
+			return nil, fmt.Errorf("schema of parameter %s not generated", param.name)
+		}
+
+		parameter := &openapi3.Parameter{
+			Name:     param.name,
+			In:       param.in,
+			Required: param.in == pathParamsType || required[fieldNames[i]],
+		}
+		if property.Ref == "" && property.Value.Description != "" {
+			parameter.Description = property.Value.Description
+			propertyValue := *property.Value
+			propertyValue.Description = ""
+			property = openapi3.NewSchemaRef("", &propertyValue)
+		}
+		if parameter.Schema, err = r.schemaVariant(property, inputSchemaVariant); err != nil {
+			return nil, err
+		}
+		parameters = append(parameters, &openapi3.ParameterRef{Value: parameter})
+	}
+	return parameters, nil
+}
+
+// getStructParameters returns the parameters of the struct fields, also of the
+// embedded structs.
+func getStructParameters(t reflect.Type) ([]structParameter, error) {
+	params := []structParameter{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		param, ok, err := getStructParameter(field)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			params = append(params, param)
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && fieldType.Kind() == reflect.Struct {
+			embedded, err := getStructParameters(fieldType)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, embedded...)
+		}
+	}
+	return params, nil
+}
+
+func getStructParameter(field reflect.StructField) (structParameter, bool, error) {
+	var param structParameter
+	var found bool
+	for _, tag := range parameterTags {
+		value, ok := field.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(value, ",")
+		if name == "-" {
+			continue
+		}
+		if found {
+			return structParameter{}, false, fmt.Errorf("field %s has both %s and %s tags", field.Name, param.in, tag)
+		}
+		if name == "" {
+			name = field.Name
+		}
+		param = structParameter{field: field, in: tag, name: name}
+		found = true
+	}
+	if found && !field.IsExported() {
+		return structParameter{}, false, fmt.Errorf("field %s of parameter %s is not exported", field.Name, param.name)
+	}
+	return param, found, nil
+}
+
+// parameterFieldTag returns the tag of the field used to reflect the schema
+// of the parameter, with the given json name and the schema tags of the field.
+func parameterFieldTag(field reflect.StructField, name string) reflect.StructTag {
+	tag := fmt.Sprintf(`json:"%s,omitempty"`, name)
+	for _, key := range []string{jsonSchemaTagName, jsonSchemaExtrasTagName, validateTagName} {
+		if value, ok := field.Tag.Lookup(key); ok {
+			tag += fmt.Sprintf(" %s:%s", key, strconv.Quote(value))
+		}
+	}
+	return reflect.StructTag(tag)
+}
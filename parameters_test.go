@@ -0,0 +1,127 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type paramsPagination struct {
+	Limit int `query:"limit" validate:"max=100"`
+}
+
+type listOrdersParams struct {
+	paramsPagination
+	UserID  string            `path:"userId" jsonschema:"description=the user id"`
+	Page    int               `query:"page,omitempty" jsonschema:"default=1,minimum=1"`
+	Sort    string            `query:"sort" jsonschema:"enum=date,enum=amount"`
+	Status  []enumOrderStatus `query:"status"`
+	Tenant  string            `header:"X-Tenant" validate:"required"`
+	Session string            `cookie:"session" jsonschema:"required"`
+	Ignored string            `json:"ignored"`
+	Skipped string            `query:"-"`
+}
+
+type paramsHeaderPage struct {
+	Page int `header:"X-Page"`
+}
+
+func TestParametersStruct(t *testing.T) {
+	t.Run("parameters from struct fields", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{
+			ValidateTags: true,
+		})
+
+		_, err := router.AddRoute(http.MethodGet, "/users/{userId}/orders", okHandler, Definitions{
+			Parameters: listOrdersParams{},
+			Querystring: ParameterValue{
+				"q": {Schema: &Schema{Value: ""}},
+			},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/parameters-struct.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("fields with the same name", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		_, err := router.AddRoute(http.MethodGet, "/orders", okHandler, Definitions{
+			Parameters: struct {
+				Page2 string `query:"page2"`
+				Page  int    `query:"page"`
+				paramsHeaderPage
+			}{},
+		})
+		require.NoError(t, err)
+
+		parameters := router.swaggerSchema.Paths.Value("/orders").Get.Parameters
+		require.Len(t, parameters, 3)
+		require.Equal(t, "page", parameters.GetByInAndName("query", "page").Name)
+		require.True(t, parameters.GetByInAndName("query", "page").Schema.Value.Type.Is("integer"))
+		require.True(t, parameters.GetByInAndName("header", "X-Page").Schema.Value.Type.Is("integer"))
+		require.True(t, parameters.GetByInAndName("query", "page2").Schema.Value.Type.Is("string"))
+	})
+
+	errorTests := []struct {
+		name          string
+		definitions   Definitions
+		expectedError string
+	}{
+		{
+			name:          "not a struct",
+			definitions:   Definitions{Parameters: "page"},
+			expectedError: fmt.Sprintf("%s: GET /users/{userId}/orders: parameters: parameters must be a struct, got string", ErrParameters),
+		},
+		{
+			name: "field with more locations",
+			definitions: Definitions{Parameters: struct {
+				ID string `query:"id" header:"id"`
+			}{}},
+			expectedError: fmt.Sprintf("%s: GET /users/{userId}/orders: parameters: field ID has both query and header tags", ErrParameters),
+		},
+		{
+			name: "unexported field",
+			definitions: Definitions{Parameters: struct {
+				id string `query:"id"`
+			}{}},
+			expectedError: fmt.Sprintf("%s: GET /users/{userId}/orders: parameters: field id of parameter id is not exported", ErrParameters),
+		},
+		{
+			name: "parameter already defined",
+			definitions: Definitions{
+				Parameters: listOrdersParams{},
+				Querystring: ParameterValue{
+					"page": {Schema: &Schema{Value: 0}},
+				},
+			},
+			expectedError: fmt.Sprintf("%s: GET /users/{userId}/orders: query parameter page: parameter is already defined", ErrParameters),
+		},
+	}
+
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{})
+
+			_, err := router.AddRoute(http.MethodGet, "/users/{userId}/orders", okHandler, test.definitions)
+			require.EqualError(t, err, test.expectedError)
+		})
+	}
+}
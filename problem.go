@@ -0,0 +1,43 @@
+package swagger
+
+import (
+	"net/http"
+
+	"github.com/davidebianchi/gswagger/problem"
+)
+
+// ProblemDetails are the problem details of RFC 9457, whose schema is the one
+// of the responses declared by ProblemResponse. They can be written by the
+// WriteProblem function of the supported routers.
+type ProblemDetails = problem.Details
+
+// ProblemResponse returns the response with the application/problem+json
+// content, whose schema is the ProblemDetails one. If extensions is not nil,
+// the schema is composed (with allOf) with the schema of extensions, which
+// declares the typed extension members. The ProblemDetails schema is referenced
+// from the components, named as the problem.Details type, if extensions is not
+// nil or UseSchemaComponents is set.
+func ProblemResponse(description string, extensions interface{}) ContentValue {
+	schema := Schema{Value: ProblemDetails{}}
+	if extensions != nil {
+		schema = Schema{
+			AllOf:                     []interface{}{ProblemDetails{}, extensions},
+			AllowAdditionalProperties: true,
+		}
+	}
+	return ContentValue{
+		Content:     Content{problem.ContentType: schema},
+		Description: description,
+	}
+}
+
+// ProblemResponses returns the ProblemResponse of each status code, described
+// by the status text. They can be used as the Definitions Responses or as the
+// DefaultResponses option.
+func ProblemResponses(extensions interface{}, statusCodes ...int) map[int]ContentValue {
+	responses := make(map[int]ContentValue, len(statusCodes))
+	for _, statusCode := range statusCodes {
+		responses[statusCode] = ProblemResponse(http.StatusText(statusCode), extensions)
+	}
+	return responses
+}
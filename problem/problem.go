@@ -0,0 +1,138 @@
+// Package problem contains the problem details of the http api errors, as
+// defined by RFC 9457 (which obsoletes RFC 7807).
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ContentType is the content type of the problem details in json format.
+const ContentType = "application/problem+json"
+
+// DefaultType is the type of the problems without a specific type, whose
+// title should be the status text.
+const DefaultType = "about:blank"
+
+var standardMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// Details are the problem details of an http api error.
+type Details struct {
+	// Type is a URI reference which identifies the problem type. If empty, it is about:blank.
+	Type string `json:"type,omitempty"`
+	// Title is a short summary of the problem type.
+	Title string `json:"title,omitempty"`
+	// Status is the http status code of the response.
+	Status int `json:"status,omitempty"`
+	// Detail is the explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference which identifies this occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+	// Extensions are the extension members, marshalled beside the standard ones.
+	// The extension members with the name of a standard member are ignored.
+	Extensions map[string]any `json:"-"`
+}
+
+// New returns the problem details of the given status code, with the status text as title.
+func New(status int, detail string) Details {
+	return Details{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// MarshalJSON marshals the standard members and the extension members.
+func (d Details) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(d.Extensions)+len(standardMembers))
+	for name, value := range d.Extensions {
+		members[name] = value
+	}
+	for _, name := range standardMembers {
+		delete(members, name)
+	}
+
+	if d.Type != "" {
+		members["type"] = d.Type
+	}
+	if d.Title != "" {
+		members["title"] = d.Title
+	}
+	if d.Status != 0 {
+		members["status"] = d.Status
+	}
+	if d.Detail != "" {
+		members["detail"] = d.Detail
+	}
+	if d.Instance != "" {
+		members["instance"] = d.Instance
+	}
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON unmarshals the standard members, and the other members in the Extensions.
+func (d *Details) UnmarshalJSON(data []byte) error {
+	type details Details
+	var standard details
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, name := range standardMembers {
+		delete(members, name)
+	}
+	if len(members) > 0 {
+		standard.Extensions = members
+	}
+	*d = Details(standard)
+	return nil
+}
+
+// OpenAPISchema returns the schema of the problem details. The extension
+// members are allowed as additional properties.
+func (Details) OpenAPISchema() *openapi3.Schema {
+	typeSchema := openapi3.NewStringSchema().WithFormat("uri-reference").WithDefault(DefaultType)
+	typeSchema.Description = "A URI reference which identifies the problem type"
+	titleSchema := openapi3.NewStringSchema()
+	titleSchema.Description = "A short summary of the problem type"
+	statusSchema := openapi3.NewIntegerSchema().WithMin(100).WithMax(599)
+	statusSchema.Description = "The http status code of the response"
+	detailSchema := openapi3.NewStringSchema()
+	detailSchema.Description = "An explanation specific to this occurrence of the problem"
+	instanceSchema := openapi3.NewStringSchema().WithFormat("uri-reference")
+	instanceSchema.Description = "A URI reference which identifies this occurrence of the problem"
+
+	return openapi3.NewObjectSchema().WithProperties(map[string]*openapi3.Schema{
+		"type":     typeSchema,
+		"title":    titleSchema,
+		"status":   statusSchema,
+		"detail":   detailSchema,
+		"instance": instanceSchema,
+	})
+}
+
+// Write writes the problem details in the response, with its status code
+// (500 if not set) and the problem json content type.
+func Write(w http.ResponseWriter, d Details) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(StatusCode(d))
+	_, err = w.Write(data)
+	return err
+}
+
+// StatusCode returns the status code of the problem details, or 500 if it is not set.
+func StatusCode(d Details) int {
+	if d.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return d.Status
+}
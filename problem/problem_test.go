@@ -0,0 +1,78 @@
+package problem
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetailsJSON(t *testing.T) {
+	t.Run("marshal standard and extension members", func(t *testing.T) {
+		details := Details{
+			Type:     "https://example.com/problems/out-of-credit",
+			Title:    "You do not have enough credit.",
+			Status:   http.StatusForbidden,
+			Detail:   "Your current balance is 30, but that costs 50.",
+			Instance: "/account/12345/msgs/abc",
+			Extensions: map[string]any{
+				"balance": 30,
+				"status":  "ignored",
+			},
+		}
+
+		data, err := json.Marshal(details)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"type": "https://example.com/problems/out-of-credit",
+			"title": "You do not have enough credit.",
+			"status": 403,
+			"detail": "Your current balance is 30, but that costs 50.",
+			"instance": "/account/12345/msgs/abc",
+			"balance": 30
+		}`, string(data))
+	})
+
+	t.Run("unmarshal standard and extension members", func(t *testing.T) {
+		var details Details
+		err := json.Unmarshal([]byte(`{"title":"Not Found","status":404,"userId":"123"}`), &details)
+		require.NoError(t, err)
+		require.Equal(t, Details{
+			Title:      "Not Found",
+			Status:     http.StatusNotFound,
+			Extensions: map[string]any{"userId": "123"},
+		}, details)
+	})
+
+	t.Run("unmarshal without extension members", func(t *testing.T) {
+		var details Details
+		err := json.Unmarshal([]byte(`{"status":500}`), &details)
+		require.NoError(t, err)
+		require.Equal(t, Details{Status: http.StatusInternalServerError}, details)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("write with the problem status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := Write(w, New(http.StatusBadRequest, "invalid name"))
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+		require.Equal(t, ContentType, w.Result().Header.Get("Content-Type"))
+		body, err := io.ReadAll(w.Result().Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title":"Bad Request","status":400,"detail":"invalid name"}`, string(body))
+	})
+
+	t.Run("write without status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := Write(w, Details{Title: "unexpected error"})
+		require.NoError(t, err)
+
+		require.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+	})
+}
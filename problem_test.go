@@ -0,0 +1,88 @@
+package swagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type problemUserNotFound struct {
+	UserID string `json:"userId"`
+}
+
+func TestProblemResponses(t *testing.T) {
+	tests := []struct {
+		name                string
+		useSchemaComponents bool
+		fixturesPath        string
+	}{
+		{
+			name:         "inline responses",
+			fixturesPath: "testdata/problem-responses.json",
+		},
+		{
+			name:                "components responses",
+			useSchemaComponents: true,
+			fixturesPath:        "testdata/problem-responses-components.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			router := newTestRouter(t, r, Options{
+				UseSchemaComponents: test.useSchemaComponents,
+				DefaultResponses:    ProblemResponses(nil, http.StatusBadRequest, http.StatusInternalServerError),
+			})
+
+			notFound := ProblemResponse("user not found", problemUserNotFound{})
+			notFound.Example = ProblemDetails{
+				Title:      "Not Found",
+				Status:     http.StatusNotFound,
+				Extensions: map[string]any{"userId": "123"},
+			}
+			_, err := router.AddRoute(http.MethodGet, "/users/{userId}", okHandler, Definitions{
+				Responses: map[int]ContentValue{
+					http.StatusOK:       {Description: "ok"},
+					http.StatusNotFound: notFound,
+				},
+			})
+			require.NoError(t, err)
+
+			err = router.GenerateAndExposeOpenapi()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			body := readBody(t, w.Result().Body)
+			expected, err := os.ReadFile(test.fixturesPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+		})
+	}
+
+	t.Run("invalid example", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		notFound := ProblemResponse("user not found", problemUserNotFound{})
+		notFound.Example = ProblemDetails{Status: http.StatusNotFound}
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			Responses: map[int]ContentValue{
+				http.StatusNotFound: notFound,
+			},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.ErrorIs(t, err, ErrValidatingOAS)
+		require.ErrorContains(t, err, "route GET /users: response 404: content application/problem+json: invalid example")
+		require.ErrorContains(t, err, `property "userId" is missing`)
+	})
+}
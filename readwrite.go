@@ -0,0 +1,221 @@
+package swagger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/jsonschema"
+)
+
+// schemaVariant is the variant of a schema used in requests or in responses.
+type schemaVariant int
+
+const (
+	// inputSchemaVariant is used in requests, without the readOnly properties.
+	inputSchemaVariant schemaVariant = iota
+	// outputSchemaVariant is used in responses, without the writeOnly properties.
+	outputSchemaVariant
+)
+
+func (v schemaVariant) suffix() string {
+	if v == inputSchemaVariant {
+		return "Input"
+	}
+	return "Output"
+}
+
+// excludes returns true if the property is not part of the variant.
+func (v schemaVariant) excludes(property *openapi3.SchemaRef) bool {
+	if property == nil || property.Value == nil {
+		return false
+	}
+	if v == inputSchemaVariant {
+		return property.Value.ReadOnly
+	}
+	return property.Value.WriteOnly
+}
+
+// setReadWriteOnly sets the readOnly and writeOnly keywords of the struct
+// fields with the readOnly or writeOnly jsonschema tags. The jsonschema lib
+// sets them only for string fields.
+func setReadWriteOnly(schema *jsonschema.Schema, definitions jsonschema.Definitions, t reflect.Type) error {
+	return walkStructFields(schema, t, definitions, func(_ *jsonschema.Schema, _ string, property *jsonschema.Schema, field reflect.StructField) error {
+		if property == jsonschema.TrueSchema || property == jsonschema.FalseSchema {
+			return nil
+		}
+		for _, tag := range strings.Split(field.Tag.Get(jsonSchemaTagName), ",") {
+			key, value, hasValue := strings.Cut(tag, "=")
+			enabled := !hasValue || value == "true"
+			switch key {
+			case "readOnly":
+				property.ReadOnly = enabled
+			case "writeOnly":
+				property.WriteOnly = enabled
+			}
+		}
+		return nil
+	})
+}
+
+func (r Router[_, _]) contentVariant(content openapi3.Content, variant schemaVariant) error {
+	for _, mediaType := range content {
+		schema, err := r.schemaVariant(mediaType.Schema, variant)
+		if err != nil {
+			return err
+		}
+		mediaType.Schema = schema
+	}
+	return nil
+}
+
+// schemaVariant returns, if SplitReadWriteSchemas option is set, the schema
+// without the properties excluded by the variant. The referenced components
+// with excluded properties are replaced by their variant, which is added to the
+// components with the name suffixed by Input or Output.
+func (r Router[_, _]) schemaVariant(schemaRef *openapi3.SchemaRef, variant schemaVariant) (*openapi3.SchemaRef, error) {
+	if !r.splitReadWriteSchemas || schemaRef == nil || !hasExcludedProperties(schemaRef, variant, map[*openapi3.Schema]bool{}) {
+		return schemaRef, nil
+	}
+	if schemaRef.Ref == "" {
+		value, err := r.variantSchema(schemaRef.Value, variant)
+		if err != nil {
+			return nil, err
+		}
+		return openapi3.NewSchemaRef("", value), nil
+	}
+
+	name, ok := strings.CutPrefix(schemaRef.Ref, schemaComponentsPrefix)
+	if !ok {
+		return schemaRef, nil
+	}
+	variantName := name + variant.suffix()
+	components := r.swaggerSchema.Components.Schemas
+	if registered, ok := components[variantName]; ok {
+		if r.schemaTypes[variantName] != r.schemaTypes[name] {
+			return nil, fmt.Errorf("schema component %s is already defined and it is not the variant of %s", variantName, name)
+		}
+		return openapi3.NewSchemaRef(schemaComponentsPrefix+variantName, registered.Value), nil
+	}
+
+	// The variant is registered before generating it, so recursive references
+	// use it too.
+	value := &openapi3.Schema{}
+	components[variantName] = openapi3.NewSchemaRef("", value)
+	r.schemaTypes[variantName] = r.schemaTypes[name]
+	variantValue, err := r.variantSchema(schemaRef.Value, variant)
+	if err != nil {
+		delete(components, variantName)
+		delete(r.schemaTypes, variantName)
+		return nil, err
+	}
+	*value = *variantValue
+	return openapi3.NewSchemaRef(schemaComponentsPrefix+variantName, value), nil
+}
+
+// variantSchema returns a copy of the schema without the properties excluded
+// by the variant, and with the variant of its sub schemas.
+func (r Router[_, _]) variantSchema(schema *openapi3.Schema, variant schemaVariant) (*openapi3.Schema, error) {
+	result := *schema
+	var err error
+
+	if schema.Properties != nil {
+		result.Properties = make(openapi3.Schemas, len(schema.Properties))
+		excluded := map[string]bool{}
+		for name, property := range schema.Properties {
+			if variant.excludes(property) {
+				excluded[name] = true
+				continue
+			}
+			if result.Properties[name], err = r.schemaVariant(property, variant); err != nil {
+				return nil, err
+			}
+		}
+		result.Required = nil
+		for _, name := range schema.Required {
+			if !excluded[name] {
+				result.Required = append(result.Required, name)
+			}
+		}
+	}
+
+	if result.Items, err = r.schemaVariant(schema.Items, variant); err != nil {
+		return nil, err
+	}
+	if result.Not, err = r.schemaVariant(schema.Not, variant); err != nil {
+		return nil, err
+	}
+	if result.AdditionalProperties.Schema, err = r.schemaVariant(schema.AdditionalProperties.Schema, variant); err != nil {
+		return nil, err
+	}
+	if result.AllOf, err = r.schemaRefsVariant(schema.AllOf, variant); err != nil {
+		return nil, err
+	}
+	if result.AnyOf, err = r.schemaRefsVariant(schema.AnyOf, variant); err != nil {
+		return nil, err
+	}
+	if result.OneOf, err = r.schemaRefsVariant(schema.OneOf, variant); err != nil {
+		return nil, err
+	}
+
+	if schema.Discriminator != nil && schema.Discriminator.Mapping != nil {
+		discriminator := *schema.Discriminator
+		discriminator.Mapping = make(openapi3.StringMap[openapi3.MappingRef], len(schema.Discriminator.Mapping))
+		for value, mappingRef := range schema.Discriminator.Mapping {
+			schemaRef := openapi3.SchemaRef(mappingRef)
+			mapped, err := r.schemaVariant(&schemaRef, variant)
+			if err != nil {
+				return nil, err
+			}
+			discriminator.Mapping[value] = openapi3.MappingRef(*mapped)
+		}
+		result.Discriminator = &discriminator
+	}
+
+	return &result, nil
+}
+
+func (r Router[_, _]) schemaRefsVariant(schemaRefs openapi3.SchemaRefs, variant schemaVariant) (openapi3.SchemaRefs, error) {
+	if schemaRefs == nil {
+		return nil, nil
+	}
+	result := make(openapi3.SchemaRefs, 0, len(schemaRefs))
+	for _, schemaRef := range schemaRefs {
+		variantRef, err := r.schemaVariant(schemaRef, variant)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, variantRef)
+	}
+	return result, nil
+}
+
+// hasExcludedProperties returns true if the schema, or one of its sub schemas,
+// has a property excluded by the variant.
+func hasExcludedProperties(schemaRef *openapi3.SchemaRef, variant schemaVariant, visited map[*openapi3.Schema]bool) bool {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return false
+	}
+	schema := schemaRef.Value
+	if visited[schema] {
+		return false
+	}
+	visited[schema] = true
+
+	for _, property := range schema.Properties {
+		if variant.excludes(property) || hasExcludedProperties(property, variant, visited) {
+			return true
+		}
+	}
+	for _, schemaRefs := range []openapi3.SchemaRefs{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, s := range schemaRefs {
+			if hasExcludedProperties(s, variant, visited) {
+				return true
+			}
+		}
+	}
+	return hasExcludedProperties(schema.Items, variant, visited) ||
+		hasExcludedProperties(schema.Not, variant, visited) ||
+		hasExcludedProperties(schema.AdditionalProperties.Schema, variant, visited)
+}
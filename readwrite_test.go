@@ -0,0 +1,159 @@
+package swagger
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type readWriteAudit struct {
+	CreatedAt time.Time `json:"createdAt" jsonschema:"readOnly"`
+	Note      string    `json:"note,omitempty"`
+}
+
+type readWriteUser struct {
+	ID       int             `json:"id" jsonschema:"readOnly=true"`
+	Name     string          `json:"name"`
+	Password string          `json:"password" jsonschema:"writeOnly"`
+	Audit    readWriteAudit  `json:"audit"`
+	Friends  []readWriteUser `json:"friends,omitempty"`
+}
+
+func TestReadWriteSchemas(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      Options
+		fixturesPath string
+	}{
+		{
+			name:         "readOnly and writeOnly properties",
+			fixturesPath: "testdata/read-write.json",
+		},
+		{
+			name: "split schemas",
+			options: Options{
+				SplitReadWriteSchemas: true,
+			},
+			fixturesPath: "testdata/read-write-split.json",
+		},
+		{
+			name: "split schemas components",
+			options: Options{
+				UseSchemaComponents:   true,
+				SplitReadWriteSchemas: true,
+			},
+			fixturesPath: "testdata/read-write-split-components.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			options := test.options
+			router := newTestRouter(t, r, options)
+
+			_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+				RequestBody: &ContentValue{
+					Content: Content{
+						jsonType: {Value: readWriteUser{}},
+					},
+				},
+				Responses: map[int]ContentValue{
+					http.StatusCreated: {
+						Content: Content{
+							jsonType: {Value: readWriteUser{}},
+						},
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+				Responses: map[int]ContentValue{
+					http.StatusOK: {
+						Content: Content{
+							jsonType: {Value: []readWriteUser{}},
+						},
+					},
+				},
+			})
+			require.NoError(t, err)
+
+			err = router.GenerateAndExposeOpenapi()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			body := readBody(t, w.Result().Body)
+			expected, err := os.ReadFile(test.fixturesPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+		})
+	}
+
+	t.Run("split schemas keep the original components", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			UseSchemaComponents:   true,
+			SplitReadWriteSchemas: true,
+		})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: readWriteUser{}},
+				},
+			},
+			Responses: map[int]ContentValue{
+				http.StatusCreated: {
+					Content: Content{
+						jsonType: {Value: readWriteUser{}},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		schemas := router.swaggerSchema.Components.Schemas
+		require.ElementsMatch(t, []string{"readWriteAudit", "readWriteAuditInput", "readWriteUser", "readWriteUserInput", "readWriteUserOutput"}, slices.Collect(maps.Keys(schemas)))
+		operation := router.swaggerSchema.Paths.Value("/users").Post
+		require.Equal(t, "#/components/schemas/readWriteUserInput", operation.RequestBody.Value.Content.Get(jsonType).Schema.Ref)
+		require.Equal(t, "#/components/schemas/readWriteUserOutput", operation.Responses.Status(http.StatusCreated).Value.Content.Get(jsonType).Schema.Ref)
+		require.Equal(t, "#/components/schemas/readWriteAudit", schemas["readWriteUserOutput"].Value.Properties["audit"].Ref)
+		require.Contains(t, schemas["readWriteUser"].Value.Properties, "id")
+		require.Contains(t, schemas["readWriteUser"].Value.Properties, "password")
+	})
+
+	t.Run("variant name already used", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			UseSchemaComponents:   true,
+			SplitReadWriteSchemas: true,
+			SchemaNamer: func(t reflect.Type) string {
+				if t == reflect.TypeOf(readWriteAudit{}) {
+					return "readWriteUserInput"
+				}
+				return ""
+			},
+		})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: readWriteUser{}},
+				},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: POST /users: request body: schema component readWriteUserInput is already defined and it is not the variant of readWriteUser", ErrRequestBody))
+	})
+}
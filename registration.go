@@ -0,0 +1,111 @@
+package swagger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+)
+
+// ErrDuplicateRoute is thrown if a route is registered with the method and an
+// equivalent path of an already registered route.
+var ErrDuplicateRoute = errors.New("duplicate route")
+
+// pathTemplateRegexp matches the path templates of the openapi paths, capturing
+// the name of the path parameter.
+var pathTemplateRegexp = regexp.MustCompile(`\{([^}]+)\}`)
+
+// DuplicateRouteError is the cause of the RouteError returned by AddRoute and
+// AddRawRoute if the route is already registered with the same method and an
+// equivalent path: the same path, or one differing only in the names of the
+// path templates (as /users/{id} and /users/{userId}).
+type DuplicateRouteError struct {
+	Method string
+	// Path is the openapi path of the duplicate route.
+	Path string
+	// Site is the file and the line which registered the duplicate route.
+	Site string
+	// RegisteredPath is the openapi path of the registered route.
+	RegisteredPath string
+	// RegisteredSite is the file and the line which registered the registered route.
+	RegisteredSite string
+}
+
+func (e *DuplicateRouteError) Error() string {
+	return fmt.Sprintf("%s: %s %s (%s) conflicts with %s %s (%s)", ErrDuplicateRoute, e.Method, e.Path, e.Site, e.Method, e.RegisteredPath, e.RegisteredSite)
+}
+
+// Unwrap returns ErrDuplicateRoute.
+func (e *DuplicateRouteError) Unwrap() error {
+	return ErrDuplicateRoute
+}
+
+// routeRegistration is a route registered in the openapi schema.
+type routeRegistration struct {
+	path        string
+	site        string
+	operationID string
+}
+
+// registrationSite returns the file and the line of the caller of the router
+// method which calls it.
+func registrationSite() string {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// routeKey returns the key of the route, equal for the routes with the same
+// method and equivalent paths: the names of the path templates do not change
+// the paths matched by the route.
+func routeKey(method, oasPath string) string {
+	return method + " " + pathTemplateRegexp.ReplaceAllString(oasPath, "{}")
+}
+
+// registerRoute registers the route with its operation id. If the route is
+// already registered, it fails with a DuplicateRouteError or, with the
+// AllowRouteReplacement option, it removes the registered operation.
+func (r Router[_, _]) registerRoute(method, oasPath, operationID, site string) error {
+	key := routeKey(method, oasPath)
+	registered, isDuplicate := r.routes[key]
+	if isDuplicate && !r.allowRouteReplacement {
+		return &sectionError{section: "route", err: &DuplicateRouteError{
+			Method:         method,
+			Path:           oasPath,
+			Site:           site,
+			RegisteredPath: registered.path,
+			RegisteredSite: registered.site,
+		}}
+	}
+
+	if isDuplicate && registered.operationID != "" {
+		delete(r.operationIDs, registered.operationID)
+	}
+	if err := r.registerOperationID(operationID, fmt.Sprintf("%s %s", method, oasPath)); err != nil {
+		if isDuplicate && registered.operationID != "" {
+			r.operationIDs[registered.operationID] = fmt.Sprintf("%s %s", method, registered.path)
+		}
+		return &sectionError{section: "operation id", err: err}
+	}
+	if isDuplicate {
+		r.removeOperation(method, registered.path)
+	}
+
+	r.routes[key] = routeRegistration{path: oasPath, site: site, operationID: operationID}
+	return nil
+}
+
+// removeOperation removes the operation from the openapi schema, with its path
+// if it has no other operations.
+func (r Router[_, _]) removeOperation(method, oasPath string) {
+	pathItem := r.swaggerSchema.Paths.Value(oasPath)
+	if pathItem == nil {
+		return
+	}
+	pathItem.SetOperation(method, nil)
+	if len(pathItem.Operations()) == 0 {
+		r.swaggerSchema.Paths.Delete(oasPath)
+	}
+}
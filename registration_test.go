@@ -0,0 +1,116 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/support/gorilla"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// nextLineSite returns the file and the line following the one of the caller.
+func nextLineSite(t *testing.T) string {
+	t.Helper()
+	_, file, line, ok := runtime.Caller(1)
+	require.True(t, ok)
+	return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+func TestDuplicateRoutes(t *testing.T) {
+	t.Run("same method and path", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		registeredSite := nextLineSite(t)
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{Summary: "list users"})
+		require.NoError(t, err)
+
+		site := nextLineSite(t)
+		_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{Summary: "other users"})
+		require.ErrorIs(t, err, ErrDuplicateRoute)
+		var duplicateErr *DuplicateRouteError
+		require.ErrorAs(t, err, &duplicateErr)
+		require.Equal(t, &DuplicateRouteError{
+			Method:         http.MethodGet,
+			Path:           "/users",
+			Site:           site,
+			RegisteredPath: "/users",
+			RegisteredSite: registeredSite,
+		}, duplicateErr)
+		require.EqualError(t, err, fmt.Sprintf("GET /users: route: duplicate route: GET /users (%s) conflicts with GET /users (%s)", site, registeredSite))
+		require.Equal(t, "list users", router.swaggerSchema.Paths.Value("/users").Get.Summary)
+
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("equivalent templated paths across sub routers", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{})
+		subRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{PathPrefix: "/v1"})
+		require.NoError(t, err)
+
+		registeredSite := nextLineSite(t)
+		_, err = subRouter.AddRoute(http.MethodGet, "/users/{id}", okHandler, Definitions{})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodGet, "/v1/users/me", okHandler, Definitions{})
+		require.NoError(t, err)
+
+		site := nextLineSite(t)
+		_, err = router.AddRawRoute(http.MethodGet, "/v1/users/{userId}", okHandler, Operation{})
+		var duplicateErr *DuplicateRouteError
+		require.ErrorAs(t, err, &duplicateErr)
+		require.Equal(t, &DuplicateRouteError{
+			Method:         http.MethodGet,
+			Path:           "/v1/users/{userId}",
+			Site:           site,
+			RegisteredPath: "/v1/users/{id}",
+			RegisteredSite: registeredSite,
+		}, duplicateErr)
+		require.Nil(t, router.swaggerSchema.Paths.Value("/v1/users/{userId}"))
+	})
+
+	t.Run("route replacement", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{AllowRouteReplacement: true})
+
+		_, err := router.AddRoute(http.MethodGet, "/users/{id}", okHandler, Definitions{OperationID: "getUser"})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodDelete, "/users/{id}", okHandler, Definitions{OperationID: "deleteUser"})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{OperationID: "listUsers"})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodGet, "/users/{userId}", okHandler, Definitions{OperationID: "getUser", Summary: "get user"})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodDelete, "/users/{userId}", okHandler, Definitions{OperationID: "listUsers"})
+		require.EqualError(t, err, fmt.Sprintf("DELETE /users/{userId}: operation id: %s: operation id listUsers of DELETE /users/{userId} is already used by GET /users", ErrOperationID))
+		_, err = router.AddRoute(http.MethodDelete, "/users/{userId}", okHandler, Definitions{OperationID: "deleteUser"})
+		require.NoError(t, err)
+
+		require.Nil(t, router.swaggerSchema.Paths.Value("/users/{id}"))
+		pathItem := router.swaggerSchema.Paths.Value("/users/{userId}")
+		require.Equal(t, "get user", pathItem.Get.Summary)
+		require.Equal(t, "deleteUser", pathItem.Delete.OperationID)
+
+		_, err = router.AddRawRoute(http.MethodGet, "/users", okHandler, Operation{&openapi3.Operation{
+			OperationID: "searchUsers",
+			Responses:   openapi3.NewResponses(),
+		}})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{OperationID: "listUsers"})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/user-id", nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+	})
+}
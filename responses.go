@@ -0,0 +1,109 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// resolveDefaultResponses returns the parent default responses merged with the
+// given ones, which override the parent ones with the same status code. If
+// UseSchemaComponents is set, the given responses are added to the components
+// responses and referenced. The responses with a Ref reference their component.
+func (r Router[_, _]) resolveDefaultResponses(parent map[int]*openapi3.ResponseRef, responses map[int]ContentValue) (map[int]*openapi3.ResponseRef, error) {
+	if len(responses) == 0 {
+		return parent, nil
+	}
+
+	result := make(map[int]*openapi3.ResponseRef, len(parent)+len(responses))
+	for statusCode, response := range parent {
+		result[statusCode] = response
+	}
+
+	statusCodes := make([]int, 0, len(responses))
+	for statusCode := range responses {
+		statusCodes = append(statusCodes, statusCode)
+	}
+	sort.Ints(statusCodes)
+
+	for _, statusCode := range statusCodes {
+		if ref := responses[statusCode].Ref; ref != "" {
+			responseRef, err := r.responseComponentRef(ref)
+			if err != nil {
+				return nil, fmt.Errorf("response %d: %w", statusCode, err)
+			}
+			result[statusCode] = responseRef
+			continue
+		}
+		response, err := r.newResponse(responses[statusCode])
+		if err != nil {
+			return nil, fmt.Errorf("response %d: %w", statusCode, err)
+		}
+		if !r.schemaComponents {
+			result[statusCode] = &openapi3.ResponseRef{Value: response}
+			continue
+		}
+		name := r.addResponseComponent(responseComponentName(statusCode), response)
+		result[statusCode] = &openapi3.ResponseRef{Ref: responseComponentsPrefix + name, Value: response}
+	}
+	return result, nil
+}
+
+// addResponseComponent adds the response to the components responses and
+// returns its name. If the name is already used, it is suffixed with a number.
+func (r Router[_, _]) addResponseComponent(name string, response *openapi3.Response) string {
+	responses := r.components().Responses
+	componentName := name
+	for i := 2; responses[componentName] != nil; i++ {
+		componentName = name + strconv.Itoa(i)
+	}
+	r.setResponseComponent(componentName, response)
+	return componentName
+}
+
+// responseComponentName returns the name of the status code text in pascal
+// case (e.g. BadRequest), or Response followed by the status code if it is unknown.
+func responseComponentName(statusCode int) string {
+	words := strings.FieldsFunc(http.StatusText(statusCode), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	if len(words) == 0 {
+		return "Response" + strconv.Itoa(statusCode)
+	}
+	var name strings.Builder
+	for _, word := range words {
+		name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return name.String()
+}
+
+// addDefaultResponses adds to the operation the default responses whose status
+// code is not declared by the route.
+func addDefaultResponses(defaultResponses map[int]*openapi3.ResponseRef, operation Operation) {
+	for statusCode, response := range defaultResponses {
+		key := strconv.Itoa(statusCode)
+		if operation.Responses.Value(key) == nil {
+			operation.Responses.Set(key, response)
+		}
+	}
+}
+
+// applicableDefaultResponses returns the default responses without the excluded status codes.
+func applicableDefaultResponses(defaultResponses map[int]*openapi3.ResponseRef, excluded []int) map[int]*openapi3.ResponseRef {
+	if len(excluded) == 0 {
+		return defaultResponses
+	}
+	result := make(map[int]*openapi3.ResponseRef, len(defaultResponses))
+	for statusCode, response := range defaultResponses {
+		result[statusCode] = response
+	}
+	for _, statusCode := range excluded {
+		delete(result, statusCode)
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/support/gorilla"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultErrorResponse struct {
+	Message string `json:"message"`
+}
+
+func TestDefaultResponses(t *testing.T) {
+	defaultResponses := map[int]ContentValue{
+		http.StatusBadRequest: {
+			Content:     Content{jsonType: {Value: defaultErrorResponse{}}},
+			Description: "bad request",
+		},
+		http.StatusInternalServerError: {
+			Content:     Content{jsonType: {Value: defaultErrorResponse{}}},
+			Description: "internal server error",
+		},
+	}
+
+	tests := []struct {
+		name                string
+		useSchemaComponents bool
+		fixturesPath        string
+	}{
+		{
+			name:         "inline responses",
+			fixturesPath: "testdata/default-responses.json",
+		},
+		{
+			name:                "components responses",
+			useSchemaComponents: true,
+			fixturesPath:        "testdata/default-responses-components.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			router := newTestRouter(t, r, Options{
+				UseSchemaComponents: test.useSchemaComponents,
+				DefaultResponses:    defaultResponses,
+			})
+
+			_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+			require.NoError(t, err)
+
+			_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+				Responses: map[int]ContentValue{
+					http.StatusCreated: {Description: "created"},
+					http.StatusBadRequest: {
+						Content:     Content{jsonType: {Value: componentUser{}}},
+						Description: "invalid user",
+					},
+				},
+				ExcludeDefaultResponses: []int{http.StatusInternalServerError},
+			})
+			require.NoError(t, err)
+
+			subRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{
+				PathPrefix: "/admin",
+				DefaultResponses: map[int]ContentValue{
+					http.StatusBadRequest: {Description: "admin bad request"},
+					http.StatusForbidden:  {Description: "forbidden"},
+				},
+			})
+			require.NoError(t, err)
+
+			_, err = subRouter.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+				Responses: map[int]ContentValue{
+					http.StatusOK: {Description: "ok"},
+				},
+			})
+			require.NoError(t, err)
+
+			err = router.GenerateAndExposeOpenapi()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			body := readBody(t, w.Result().Body)
+			expected, err := os.ReadFile(test.fixturesPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+		})
+	}
+
+	t.Run("invalid default response", func(t *testing.T) {
+		_, err := NewRouter(gorilla.NewRouter(mux.NewRouter()), Options{
+			Openapi: getBaseSwagger(t),
+			DefaultResponses: map[int]ContentValue{
+				http.StatusBadRequest: {
+					Content:  Content{jsonType: {Value: ""}},
+					Example:  "example",
+					Examples: Examples{"example": {Value: "example"}},
+				},
+			},
+		})
+		require.EqualError(t, err, fmt.Sprintf("%s: default responses: response 400: example and examples are mutually exclusive", ErrResponses))
+	})
+}
+
+func TestResponseComponentName(t *testing.T) {
+	require.Equal(t, "BadRequest", responseComponentName(http.StatusBadRequest))
+	require.Equal(t, "NonAuthoritativeInformation", responseComponentName(http.StatusNonAuthoritativeInfo))
+	require.Equal(t, "Response499", responseComponentName(499))
+}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"path"
 	"regexp"
+	"slices"
 	"sort"
-	"strings"
+	"strconv"
 
 	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/invopop/jsonschema"
 )
 
 var (
@@ -21,16 +21,32 @@ var (
 	ErrPathParams = errors.New("errors generating path parameters schema")
 	// ErrQuerystring is thrown if error occurs generating querystring params schemas.
 	ErrQuerystring = errors.New("errors generating querystring schema")
+	// ErrHeaders is thrown if error occurs generating header params schemas.
+	ErrHeaders = errors.New("errors generating headers schema")
+	// ErrCookies is thrown if error occurs generating cookie params schemas.
+	ErrCookies = errors.New("errors generating cookies schema")
+	// ErrParameters is thrown if error occurs generating the parameters of the Parameters struct.
+	ErrParameters = errors.New("errors generating parameters schema")
+	// ErrSecurity is thrown if the security requirements use undefined security schemes or scopes.
+	ErrSecurity = errors.New("errors checking security requirements")
 )
 
 // AddRawRoute add route to router with specific method, path and handler. Add the
-// router also to the openapi schema, after validating it
+// router also to the openapi schema, after validating it (except the examples,
+// validated by GenerateAndExposeOpenapi)
 func (r Router[HandlerFunc, Route]) AddRawRoute(method string, routePath string, handler HandlerFunc, operation Operation) (Route, error) {
+	return r.addRoute(method, routePath, handler, operation, registrationSite())
+}
+
+func (r Router[HandlerFunc, Route]) addRoute(method string, routePath string, handler HandlerFunc, operation Operation, site string) (Route, error) {
+	pathWithPrefix := path.Join(r.pathPrefix, routePath)
+	oasPath := r.router.TransformPathToOasPath(pathWithPrefix)
 	op := operation.Operation
 	if op != nil {
-		err := operation.Validate(r.context)
+		// The examples are validated by GenerateAndExposeOpenapi.
+		err := operation.Validate(r.context, append(r.validationOptions(), openapi3.DisableExamplesValidation())...)
 		if err != nil {
-			return getZero[Route](), err
+			return getZero[Route](), setRoute(newRouteError(nil, "operation", err), method, oasPath)
 		}
 	} else {
 		op = openapi3.NewOperation()
@@ -38,8 +54,9 @@ func (r Router[HandlerFunc, Route]) AddRawRoute(method string, routePath string,
 			op.Responses = openapi3.NewResponses()
 		}
 	}
-	pathWithPrefix := path.Join(r.pathPrefix, routePath)
-	oasPath := r.router.TransformPathToOasPath(pathWithPrefix)
+	if err := r.registerRoute(method, oasPath, op.OperationID, site); err != nil {
+		return getZero[Route](), setRoute(newRouteError(nil, "route", err), method, oasPath)
+	}
 	r.swaggerSchema.AddOperation(oasPath, method, op)
 
 	// Handle, when content-type is json, the request/response marshalling? Maybe with a specific option.
@@ -51,15 +68,49 @@ func (r Router[HandlerFunc, Route]) AddRawRoute(method string, routePath string,
 type Content map[string]Schema
 
 // Schema contains the value and if properties allow additional properties.
+// The schema could also be composed by the schemas of the values in OneOf, AnyOf
+// or AllOf, which are referenced from the components. In this case, Value must be empty.
 type Schema struct {
 	Value                     interface{}
 	AllowAdditionalProperties bool
+
+	OneOf         []interface{}
+	AnyOf         []interface{}
+	AllOf         []interface{}
+	Discriminator *Discriminator
+
+	// Example and Examples are set in the media type or in the parameter which
+	// uses the schema. They are mutually exclusive.
+	Example  interface{}
+	Examples Examples
+}
+
+// Discriminator of a composed schema.
+type Discriminator struct {
+	// PropertyName is the name of the property which holds the discriminator value.
+	PropertyName string
+	// Mapping maps the discriminator values to the value whose schema is used.
+	Mapping map[string]interface{}
 }
 
 type Parameter struct {
 	Content     Content
 	Schema      *Schema
 	Description string
+	// Required is always true for path parameters.
+	Required   bool
+	Deprecated bool
+	// Style describes how the parameter is serialized (e.g. form, spaceDelimited,
+	// pipeDelimited or deepObject, see the openapi3.Serialization constants).
+	// Default to form for query and cookie parameters, simple for path and header parameters.
+	Style string
+	// Explode, if set, overrides the default of the style (true only for form).
+	Explode         *bool
+	AllowEmptyValue bool
+	AllowReserved   bool
+	// Example and Examples of the parameter. If not set, the ones of the schema are used.
+	Example  interface{}
+	Examples Examples
 }
 
 // ParameterValue is the struct containing the schema or the content information.
@@ -68,8 +119,40 @@ type ParameterValue map[string]Parameter
 
 // ContentValue is the struct containing the content information.
 type ContentValue struct {
+	// Ref is the name of the request body or response component, added with
+	// AddRequestBodyComponent or AddResponseComponent. If set, the component
+	// is referenced and the other fields are ignored.
+	Ref         string
 	Content     Content
 	Description string
+	// Example and Examples are set in the content types which have not their own.
+	Example  interface{}
+	Examples Examples
+	// Headers and Links of the response, by name. They are supported only in responses.
+	Headers map[string]Header
+	Links   map[string]Link
+}
+
+// Header of a response. If Schema is not set, the header accepts any value.
+type Header struct {
+	// Ref is the name of the header component, added with AddHeaderComponent.
+	// If set, the component is referenced and the other fields are ignored.
+	Ref         string
+	Schema      *Schema
+	Description string
+	Required    bool
+	Deprecated  bool
+}
+
+// Link from a response to an operation, identified by OperationID or OperationRef.
+// Parameters and RequestBody are values or runtime expressions (e.g. $response.body#/id)
+// passed to the linked operation.
+type Link struct {
+	OperationID  string
+	OperationRef string
+	Description  string
+	Parameters   map[string]interface{}
+	RequestBody  interface{}
 }
 
 type SecurityRequirements []SecurityRequirement
@@ -81,7 +164,10 @@ type Definitions struct {
 	// Specification extensions https://github.com/OAI/OpenAPI-Specification/blob/main/versions/3.0.3.md#specification-extensions
 	Extensions map[string]interface{}
 	// Optional field for documentation
-	Tags        []string
+	Tags []string
+	// OperationID is the unique id of the operation. If empty, it is set by the
+	// router OperationIDStrategy.
+	OperationID string
 	Summary     string
 	Description string
 	Deprecated  bool
@@ -91,9 +177,26 @@ type Definitions struct {
 	Querystring ParameterValue
 	Headers     ParameterValue
 	Cookies     ParameterValue
-	RequestBody *ContentValue
-	Responses   map[int]ContentValue
-
+	// Parameters is a struct whose fields, tagged with query, header, path or cookie
+	// (e.g. `query:"page"`), are added as parameters with the name set in the tag.
+	// Their schema, description and required keyword are reflected from the field.
+	Parameters interface{}
+	// ParameterRefs contains the names of the parameter components, added with
+	// AddParameterComponent, referenced by the route.
+	ParameterRefs []string
+	RequestBody   *ContentValue
+	Responses     map[int]ContentValue
+	// RangeResponses contains the responses of the status code ranges: 1XX, 2XX, 3XX, 4XX or 5XX.
+	RangeResponses map[string]ContentValue
+	// DefaultResponse is the response of the status codes not declared otherwise.
+	DefaultResponse *ContentValue
+	// ExcludeDefaultResponses contains the status codes of the router DefaultResponses
+	// option not added to the route. The status codes declared in Responses
+	// always override the router ones.
+	ExcludeDefaultResponses []int
+
+	// Security overrides the default security requirements of the router.
+	// The public routes can set NoSecurity.
 	Security SecurityRequirements
 }
 
@@ -101,6 +204,7 @@ func newOperationFromDefinition(schema Definitions) Operation {
 	operation := NewOperation()
 	operation.Responses = &openapi3.Responses{}
 	operation.Tags = schema.Tags
+	operation.OperationID = schema.OperationID
 	operation.Extensions = schema.Extensions
 	operation.addSecurityRequirements(schema.Security)
 	operation.Description = schema.Description
@@ -110,6 +214,10 @@ func newOperationFromDefinition(schema Definitions) Operation {
 	return operation
 }
 
+const defaultResponseKey = "default"
+
+var statusCodeRangeRegexp = regexp.MustCompile(`^[1-5]XX$`)
+
 const (
 	pathParamsType  = "path"
 	queryParamType  = "query"
@@ -118,91 +226,153 @@ const (
 )
 
 // AddRoute add a route with json schema inferred by passed schema.
-func (r Router[HandlerFunc, Route]) AddRoute(method string, path string, handler HandlerFunc, schema Definitions) (Route, error) {
-	operation := newOperationFromDefinition(schema)
-
-	err := r.resolveRequestBodySchema(schema.RequestBody, operation)
+func (r Router[HandlerFunc, Route]) AddRoute(method string, routePath string, handler HandlerFunc, schema Definitions) (Route, error) {
+	oasPath := r.router.TransformPathToOasPath(path.Join(r.pathPrefix, routePath))
+	staged, commitComponents := r.stageComponents()
+	operation, err := staged.newOperation(oasPath, schema, r.defaultResponses)
 	if err != nil {
-		return getZero[Route](), fmt.Errorf("%w: %s", ErrRequestBody, err)
+		return getZero[Route](), setRoute(err, method, oasPath)
+	}
+	if operation.OperationID == "" && r.operationIDStrategy != nil {
+		operation.OperationID = r.operationIDStrategy(method, oasPath, handler)
+	}
+	if r.securityAuthenticators != nil {
+		if handler, err = r.withSecurity(handler, operation); err != nil {
+			return getZero[Route](), setRoute(newRouteError(ErrSecurity, "security", err), method, oasPath)
+		}
 	}
 
-	err = r.resolveResponsesSchema(schema.Responses, operation)
+	route, err := r.addRoute(method, routePath, handler, operation, registrationSite())
 	if err != nil {
-		return getZero[Route](), fmt.Errorf("%w: %s", ErrResponses, err)
+		return getZero[Route](), err
 	}
+	commitComponents()
+	return route, nil
+}
 
-	oasPath := r.router.TransformPathToOasPath(path)
-	err = r.resolveParameterSchema(pathParamsType, getPathParamsAutoComplete(schema, oasPath), operation)
-	if err != nil {
-		return getZero[Route](), fmt.Errorf("%w: %s", ErrPathParams, err)
+// newOperation returns the operation with the schemas inferred by the definitions,
+// and with the default responses not declared or excluded by the definitions.
+func (r Router[_, _]) newOperation(oasPath string, schema Definitions, defaultResponses map[int]*openapi3.ResponseRef) (Operation, error) {
+	operation := newOperationFromDefinition(schema)
+
+	if err := r.checkSecurityRequirements(r.operationSecurity(operation)); err != nil {
+		return Operation{}, newRouteError(ErrSecurity, "security", err)
 	}
 
-	err = r.resolveParameterSchema(queryParamType, schema.Querystring, operation)
+	err := r.resolveRequestBodySchema(schema.RequestBody, operation)
 	if err != nil {
-		return getZero[Route](), fmt.Errorf("%w: %s", ErrPathParams, err)
+		return Operation{}, newRouteError(ErrRequestBody, "request body", err)
 	}
 
-	err = r.resolveParameterSchema(headerParamType, schema.Headers, operation)
+	defaultResponses = applicableDefaultResponses(defaultResponses, schema.ExcludeDefaultResponses)
+	responses := schema.Responses
+	if responses == nil && (schema.RangeResponses != nil || schema.DefaultResponse != nil || len(defaultResponses) > 0) {
+		responses = map[int]ContentValue{}
+	}
+	err = r.resolveResponsesSchema(responses, operation)
 	if err != nil {
-		return getZero[Route](), fmt.Errorf("%w: %s", ErrPathParams, err)
+		return Operation{}, newRouteError(ErrResponses, "responses", err)
 	}
-
-	err = r.resolveParameterSchema(cookieParamType, schema.Cookies, operation)
+	err = r.resolveRangeResponsesSchema(schema.RangeResponses, schema.DefaultResponse, operation)
 	if err != nil {
-		return getZero[Route](), fmt.Errorf("%w: %s", ErrPathParams, err)
+		return Operation{}, newRouteError(ErrResponses, "responses", err)
 	}
+	addDefaultResponses(defaultResponses, operation)
 
-	return r.AddRawRoute(method, path, handler, operation)
-}
-
-func (r Router[_, _]) getSchemaFromInterface(v interface{}, allowAdditionalProperties bool) (*openapi3.Schema, error) {
-	if v == nil {
-		return &openapi3.Schema{}, nil
+	// The parameters of the struct and the referenced components are added
+	// after the ones of the ParameterValue maps.
+	extraParameters, err := r.resolveParametersStruct(schema.Parameters)
+	if err != nil {
+		return Operation{}, newRouteError(ErrParameters, "parameters", err)
 	}
-
-	reflector := &jsonschema.Reflector{
-		DoNotReference:            true,
-		AllowAdditionalProperties: allowAdditionalProperties,
-		Anonymous:                 true,
+	refParameters, err := r.parameterComponentRefs(schema.ParameterRefs)
+	if err != nil {
+		return Operation{}, newRouteError(ErrParameters, "parameter refs", err)
+	}
+	extraParameters = append(extraParameters, refParameters...)
+
+	// The path parameters not declared are autocompleted, if they are not
+	// defined by the struct or the referenced components.
+	pathParams := getPathParamsAutoComplete(schema, oasPath)
+	for _, param := range extraParameters {
+		if _, ok := schema.PathParams[param.Value.Name]; param.Value.In == pathParamsType && !ok {
+			delete(pathParams, param.Value.Name)
+		}
+	}
+	parameterValues := []struct {
+		paramType string
+		params    ParameterValue
+		kind      error
+	}{
+		{paramType: pathParamsType, params: pathParams, kind: ErrPathParams},
+		{paramType: queryParamType, params: schema.Querystring, kind: ErrQuerystring},
+		{paramType: headerParamType, params: schema.Headers, kind: ErrHeaders},
+		{paramType: cookieParamType, params: schema.Cookies, kind: ErrCookies},
+	}
+	for _, v := range parameterValues {
+		if err := r.resolveParameterSchema(v.paramType, v.params, operation); err != nil {
+			return Operation{}, newRouteError(v.kind, v.paramType+" parameters", err)
+		}
 	}
 
-	jsonSchema := reflector.Reflect(v)
-	jsonSchema.Version = ""
-	// Empty definitions. Definitions are not valid in openapi3, which use components.
-	// In the future, we could add an option to fill the components in openapi spec.
-	jsonSchema.Definitions = nil
-
-	data, err := jsonSchema.MarshalJSON()
-	if err != nil {
-		return nil, err
+	for _, param := range extraParameters {
+		if operation.Parameters.GetByInAndName(param.Value.In, param.Value.Name) != nil {
+			return Operation{}, newRouteError(ErrParameters, fmt.Sprintf("%s parameter %s", param.Value.In, param.Value.Name), errors.New("parameter is already defined"))
+		}
+		operation.Parameters = append(operation.Parameters, param)
 	}
 
-	schema := openapi3.NewSchema()
-	err = schema.UnmarshalJSON(data)
-	if err != nil {
-		return nil, err
+	// The webhooks have no path.
+	if oasPath != "" {
+		if err := checkPathParams(oasPath, operation.Parameters); err != nil {
+			return Operation{}, newRouteError(ErrPathParams, "path parameters", err)
+		}
 	}
 
-	return schema, nil
+	return operation, nil
 }
 
 func (r Router[_, _]) resolveRequestBodySchema(bodySchema *ContentValue, operation Operation) error {
 	if bodySchema == nil {
 		return nil
 	}
-	content, err := r.addContentToOASSchema(bodySchema.Content)
+	if bodySchema.Ref != "" {
+		requestBodyRef, err := r.requestBodyComponentRef(bodySchema.Ref)
+		if err != nil {
+			return err
+		}
+		operation.RequestBody = requestBodyRef
+		return nil
+	}
+	requestBody, err := r.newRequestBody(*bodySchema)
 	if err != nil {
 		return err
 	}
+	operation.AddRequestBody(requestBody)
+	return nil
+}
+
+func (r Router[_, _]) newRequestBody(v ContentValue) (*openapi3.RequestBody, error) {
+	if v.Headers != nil || v.Links != nil {
+		return nil, fmt.Errorf("headers and links are supported only in responses")
+	}
+	content, err := r.addContentToOASSchema(v.Content)
+	if err != nil {
+		return nil, err
+	}
+	if err := setContentExamples(content, v.Example, v.Examples); err != nil {
+		return nil, err
+	}
+	if err := r.contentVariant(content, inputSchemaVariant); err != nil {
+		return nil, err
+	}
 
 	requestBody := openapi3.NewRequestBody().WithContent(content)
 
-	if bodySchema.Description != "" {
-		requestBody.WithDescription(bodySchema.Description)
+	if v.Description != "" {
+		requestBody.WithDescription(v.Description)
 	}
-
-	operation.AddRequestBody(requestBody)
-	return nil
+	return requestBody, nil
 }
 
 func (r Router[_, _]) resolveResponsesSchema(responses map[int]ContentValue, operation Operation) error {
@@ -210,21 +380,152 @@ func (r Router[_, _]) resolveResponsesSchema(responses map[int]ContentValue, ope
 		operation.Responses = openapi3.NewResponses()
 	}
 	for statusCode, v := range responses {
-		response := openapi3.NewResponse()
-		content, err := r.addContentToOASSchema(v.Content)
+		if v.Ref != "" {
+			responseRef, err := r.responseComponentRef(v.Ref)
+			if err != nil {
+				return &sectionError{section: fmt.Sprintf("response %d", statusCode), err: err}
+			}
+			operation.Responses.Set(strconv.Itoa(statusCode), responseRef)
+			continue
+		}
+		response, err := r.newResponse(v)
 		if err != nil {
-			return err
+			return &sectionError{section: fmt.Sprintf("response %d", statusCode), err: err}
 		}
-		response = response.WithContent(content)
-		response = response.WithDescription(v.Description)
-
 		operation.AddResponse(statusCode, response)
 	}
 
 	return nil
 }
 
+// resolveRangeResponsesSchema adds to the operation the responses of the status
+// code ranges (e.g. 4XX) and the default response.
+func (r Router[_, _]) resolveRangeResponsesSchema(rangeResponses map[string]ContentValue, defaultResponse *ContentValue, operation Operation) error {
+	for statusCodeRange, v := range rangeResponses {
+		if !statusCodeRangeRegexp.MatchString(statusCodeRange) {
+			return &sectionError{section: "response " + statusCodeRange, err: fmt.Errorf("invalid status code range %s", statusCodeRange)}
+		}
+		responseRef, err := r.newResponseRef(v)
+		if err != nil {
+			return &sectionError{section: "response " + statusCodeRange, err: err}
+		}
+		operation.Responses.Set(statusCodeRange, responseRef)
+	}
+
+	if defaultResponse != nil {
+		responseRef, err := r.newResponseRef(*defaultResponse)
+		if err != nil {
+			return &sectionError{section: "response " + defaultResponseKey, err: err}
+		}
+		operation.Responses.Set(defaultResponseKey, responseRef)
+	}
+	return nil
+}
+
+// newResponseRef returns the reference to the response component, if the
+// Ref is set, or the response.
+func (r Router[_, _]) newResponseRef(v ContentValue) (*openapi3.ResponseRef, error) {
+	if v.Ref != "" {
+		return r.responseComponentRef(v.Ref)
+	}
+	response, err := r.newResponse(v)
+	if err != nil {
+		return nil, err
+	}
+	return &openapi3.ResponseRef{Value: response}, nil
+}
+
+func (r Router[_, _]) newResponse(v ContentValue) (*openapi3.Response, error) {
+	response := openapi3.NewResponse()
+	content, err := r.addContentToOASSchema(v.Content)
+	if err != nil {
+		return nil, err
+	}
+	if err := setContentExamples(content, v.Example, v.Examples); err != nil {
+		return nil, err
+	}
+	if err := r.contentVariant(content, outputSchemaVariant); err != nil {
+		return nil, err
+	}
+	response = response.WithContent(content)
+	response = response.WithDescription(v.Description)
+	if response.Headers, err = r.resolveResponseHeaders(v.Headers); err != nil {
+		return nil, err
+	}
+	response.Links = resolveResponseLinks(v.Links)
+	return response, nil
+}
+
+func (r Router[_, _]) resolveResponseHeaders(headers map[string]Header) (openapi3.Headers, error) {
+	if headers == nil {
+		return nil, nil
+	}
+	oasHeaders := make(openapi3.Headers, len(headers))
+	for name, v := range headers {
+		if v.Ref != "" {
+			headerRef, err := r.headerComponentRef(v.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("header %s: %w", name, err)
+			}
+			oasHeaders[name] = headerRef
+			continue
+		}
+		header, err := r.newHeader(v)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		oasHeaders[name] = &openapi3.HeaderRef{Value: header}
+	}
+	return oasHeaders, nil
+}
+
+func (r Router[_, _]) newHeader(v Header) (*openapi3.Header, error) {
+	header := &openapi3.Header{
+		Parameter: openapi3.Parameter{
+			Description: v.Description,
+			Required:    v.Required,
+			Deprecated:  v.Deprecated,
+			Schema:      openapi3.NewSchemaRef("", openapi3.NewSchema()),
+		},
+	}
+	if v.Schema != nil {
+		schema, err := r.resolveSchema(*v.Schema)
+		if err != nil {
+			return nil, err
+		}
+		if header.Schema, err = r.schemaVariant(schema, outputSchemaVariant); err != nil {
+			return nil, err
+		}
+		if header.Example, header.Examples, err = marshalExamples(v.Schema.Example, v.Schema.Examples); err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+func resolveResponseLinks(links map[string]Link) openapi3.Links {
+	if links == nil {
+		return nil
+	}
+	oasLinks := make(openapi3.Links, len(links))
+	for name, v := range links {
+		oasLinks[name] = &openapi3.LinkRef{
+			Value: &openapi3.Link{
+				OperationID:  v.OperationID,
+				OperationRef: v.OperationRef,
+				Description:  v.Description,
+				Parameters:   v.Parameters,
+				RequestBody:  v.RequestBody,
+			},
+		}
+	}
+	return oasLinks
+}
+
 func (r Router[_, _]) resolveParameterSchema(paramType string, paramConfig ParameterValue, operation Operation) error {
+	if !slices.Contains(parameterTags, paramType) {
+		return fmt.Errorf("invalid param type")
+	}
 	var keys = make([]string, 0, len(paramConfig))
 	for k := range paramConfig {
 		keys = append(keys, k)
@@ -232,82 +533,138 @@ func (r Router[_, _]) resolveParameterSchema(paramType string, paramConfig Param
 	sort.Strings(keys)
 
 	for _, key := range keys {
-		v := paramConfig[key]
-		var param *openapi3.Parameter
-		switch paramType {
-		case pathParamsType:
-			param = openapi3.NewPathParameter(key)
-		case queryParamType:
-			param = openapi3.NewQueryParameter(key)
-		case headerParamType:
-			param = openapi3.NewHeaderParameter(key)
-		case cookieParamType:
-			param = openapi3.NewCookieParameter(key)
-		default:
-			return fmt.Errorf("invalid param type")
+		param, err := r.newParameter(paramType, key, paramConfig[key])
+		if err != nil {
+			return &sectionError{section: fmt.Sprintf("%s parameter %s", paramType, key), err: err}
 		}
+		operation.AddParameter(param)
+	}
 
-		if v.Description != "" {
-			param = param.WithDescription(v.Description)
-		}
+	return nil
+}
+
+func (r Router[_, _]) newParameter(paramType string, name string, v Parameter) (*openapi3.Parameter, error) {
+	var param *openapi3.Parameter
+	switch paramType {
+	case pathParamsType:
+		param = openapi3.NewPathParameter(name)
+	case queryParamType:
+		param = openapi3.NewQueryParameter(name)
+	case headerParamType:
+		param = openapi3.NewHeaderParameter(name)
+	case cookieParamType:
+		param = openapi3.NewCookieParameter(name)
+	default:
+		return nil, fmt.Errorf("invalid param type")
+	}
 
-		if v.Content != nil {
-			content, err := r.addContentToOASSchema(v.Content)
+	if v.Description != "" {
+		param = param.WithDescription(v.Description)
+	}
+	if v.Required {
+		param = param.WithRequired(true)
+	}
+	param.Deprecated = v.Deprecated
+	param.Style = v.Style
+	param.Explode = v.Explode
+	param.AllowEmptyValue = v.AllowEmptyValue
+	param.AllowReserved = v.AllowReserved
+
+	if v.Content != nil {
+		content, err := r.addContentToOASSchema(v.Content)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.contentVariant(content, inputSchemaVariant); err != nil {
+			return nil, err
+		}
+		param.Content = content
+	} else {
+		schema := openapi3.NewSchemaRef("", openapi3.NewSchema())
+		if v.Schema != nil {
+			var err error
+			schema, err = r.resolveSchema(*v.Schema)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			param.Content = content
-		} else {
-			schema := openapi3.NewSchema()
-			if v.Schema != nil {
-				var err error
-				schema, err = r.getSchemaFromInterface(v.Schema.Value, v.Schema.AllowAdditionalProperties)
-				if err != nil {
-					return err
-				}
+			if schema, err = r.schemaVariant(schema, inputSchemaVariant); err != nil {
+				return nil, err
+			}
+			if v.Example == nil && v.Examples == nil {
+				v.Example, v.Examples = v.Schema.Example, v.Schema.Examples
 			}
-			param.WithSchema(schema)
 		}
-
-		operation.AddParameter(param)
+		param.Schema = schema
 	}
 
-	return nil
+	var err error
+	if param.Example, param.Examples, err = marshalExamples(v.Example, v.Examples); err != nil {
+		return nil, err
+	}
+	return param, nil
 }
 
 func (r Router[_, _]) addContentToOASSchema(content Content) (openapi3.Content, error) {
 	oasContent := openapi3.NewContent()
 	for k, v := range content {
 		var err error
-		schema, err := r.getSchemaFromInterface(v.Value, v.AllowAdditionalProperties)
+		schema, err := r.resolveSchema(v)
 		if err != nil {
 			return nil, err
 		}
-		oasContent[k] = openapi3.NewMediaType().WithSchema(schema)
+		mediaType := openapi3.NewMediaType().WithSchemaRef(schema)
+		if mediaType.Example, mediaType.Examples, err = marshalExamples(v.Example, v.Examples); err != nil {
+			return nil, err
+		}
+		oasContent[k] = mediaType
 	}
 	return oasContent, nil
 }
 
+// getPathParamsAutoComplete returns the path params of the definitions with,
+// for each parameter of the path not declared, a string parameter.
 func getPathParamsAutoComplete(schema Definitions, path string) ParameterValue {
-	if schema.PathParams == nil {
-		re := regexp.MustCompile(`\{([^}]+)\}`)
-		segments := strings.Split(path, "/")
-		for _, segment := range segments {
-			params := re.FindAllStringSubmatch(segment, -1)
-			if len(params) == 0 {
-				continue
-			}
-			if schema.PathParams == nil {
-				schema.PathParams = make(ParameterValue)
-			}
-			for _, param := range params {
-				schema.PathParams[param[1]] = Parameter{
-					Schema: &Schema{Value: ""},
-				}
+	names := getPathParamNames(path)
+	if len(names) == 0 {
+		return schema.PathParams
+	}
+	pathParams := make(ParameterValue, len(names))
+	for name, param := range schema.PathParams {
+		pathParams[name] = param
+	}
+	for _, name := range names {
+		if _, ok := pathParams[name]; !ok {
+			pathParams[name] = Parameter{
+				Schema: &Schema{Value: ""},
 			}
 		}
 	}
-	return schema.PathParams
+	return pathParams
+}
+
+// getPathParamNames returns the names of the parameters of the path templates.
+func getPathParamNames(path string) []string {
+	names := []string{}
+	for _, param := range pathTemplateRegexp.FindAllStringSubmatch(path, -1) {
+		names = append(names, param[1])
+	}
+	return names
+}
+
+// checkPathParams checks that the path parameters of the operation are
+// parameters of the path.
+func checkPathParams(path string, parameters openapi3.Parameters) error {
+	names := getPathParamNames(path)
+	for _, param := range parameters {
+		if param.Value == nil || param.Value.In != pathParamsType || slices.Contains(names, param.Value.Name) {
+			continue
+		}
+		return &sectionError{
+			section: fmt.Sprintf("%s parameter %s", pathParamsType, param.Value.Name),
+			err:     fmt.Errorf("parameter is not in the path %s", path),
+		}
+	}
+	return nil
 }
 
 func getZero[T any]() T {
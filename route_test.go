@@ -427,6 +427,104 @@ func TestAddRoutes(t *testing.T) {
 			testMethod:   http.MethodPost,
 			fixturesPath: "testdata/oneOf.json",
 		},
+		{
+			name: "oneOf, anyOf and allOf with discriminator",
+			routes: func(t *testing.T, router *TestRouter) {
+				type Cat struct {
+					Kind  string `json:"kind" jsonschema:"enum=cat"`
+					Lives int    `json:"lives"`
+				}
+				type Dog struct {
+					Kind  string `json:"kind" jsonschema:"enum=dog"`
+					Breed string `json:"breed"`
+				}
+				type Named struct {
+					Name string `json:"name"`
+				}
+
+				_, err := router.AddRoute(http.MethodPost, "/pets", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {
+								OneOf: []interface{}{Cat{}, &Dog{}},
+								Discriminator: &Discriminator{
+									PropertyName: "kind",
+									Mapping: map[string]interface{}{
+										"cat": Cat{},
+										"dog": Dog{},
+									},
+								},
+							},
+						},
+					},
+					Responses: map[int]ContentValue{
+						200: {
+							Content: Content{
+								jsonType: {
+									AllOf: []interface{}{Named{}, Dog{}},
+								},
+							},
+						},
+					},
+					Querystring: ParameterValue{
+						"id": {
+							Schema: &Schema{
+								AnyOf: []interface{}{"", 0},
+							},
+						},
+					},
+				})
+				require.NoError(t, err)
+			},
+			testPath:     "/pets",
+			testMethod:   http.MethodPost,
+			fixturesPath: "testdata/composition.json",
+		},
+		{
+			name: "default and range responses",
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+					Responses: map[int]ContentValue{
+						200: {
+							Content: Content{
+								jsonType: {Value: Users{}},
+							},
+						},
+					},
+					RangeResponses: map[string]ContentValue{
+						"4XX": {
+							Content: Content{
+								jsonType: {Value: errorResponse{}},
+							},
+							Description: "client error",
+						},
+					},
+					DefaultResponse: &ContentValue{
+						Content: Content{
+							jsonType: {Value: errorResponse{}},
+						},
+						Description: "unexpected error",
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodDelete, "/users", okHandler, Definitions{
+					RangeResponses: map[string]ContentValue{
+						"5XX": {Description: "server error"},
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodPut, "/users", okHandler, Definitions{
+					RangeResponses: map[string]ContentValue{
+						"4xx": {Description: "client error"},
+					},
+				})
+				require.EqualError(t, err, fmt.Sprintf("%s: PUT /users: response 4xx: invalid status code range 4xx", ErrResponses))
+			},
+			testPath:     "/users",
+			fixturesPath: "testdata/range-responses.json",
+		},
 		{
 			name: "schema with tags",
 			routes: func(t *testing.T, router *TestRouter) {
@@ -441,7 +539,20 @@ func TestAddRoutes(t *testing.T) {
 		{
 			name: "schema with security",
 			routes: func(t *testing.T, router *TestRouter) {
-				_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+				err := router.AddSecurityScheme("api_key", APIKeySecurityScheme("header", "X-API-Key"))
+				require.NoError(t, err)
+				err = router.AddSecurityScheme("auth", OAuth2SecurityScheme(OAuthFlows{
+					ClientCredentials: &OAuthFlow{
+						TokenURL: "https://example.com/oauth/token",
+						Scopes: map[string]string{
+							"resource.read":  "read the resources",
+							"resource.write": "write the resources",
+						},
+					},
+				}))
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
 					Security: SecurityRequirements{
 						SecurityRequirement{
 							"api_key": []string{},
@@ -481,7 +592,7 @@ func TestAddRoutes(t *testing.T) {
 						},
 					},
 				})
-				require.EqualError(t, err, "extra sibling fields: [extension-field]")
+				require.EqualError(t, err, "GET /: operation: extra sibling fields: [extension-field]")
 			},
 			fixturesPath: "testdata/empty.json",
 		},
@@ -692,6 +803,18 @@ func TestResolveRequestBodySchema(t *testing.T) {
 				"responses": null
 			}`,
 		},
+		{
+			name: "headers are not supported",
+			bodySchema: &ContentValue{
+				Content: Content{
+					jsonType: {Value: ""},
+				},
+				Headers: map[string]Header{
+					"X-Request-Id": {},
+				},
+			},
+			expectedErr: fmt.Errorf("headers and links are supported only in responses"),
+		},
 	}
 
 	mux := mux.NewRouter()
@@ -916,6 +1039,72 @@ func TestResolveResponsesSchema(t *testing.T) {
 				}
 			}`,
 		},
+		{
+			name: "with headers and links",
+			responsesSchema: map[int]ContentValue{
+				201: {
+					Description: "created",
+					Headers: map[string]Header{
+						"Location": {
+							Schema:      &Schema{Value: "", Example: "/users/1"},
+							Description: "the url of the created user",
+							Required:    true,
+						},
+						"X-RateLimit-Remaining": {
+							Schema: &Schema{Value: 0},
+						},
+						"X-Legacy": {
+							Deprecated: true,
+						},
+					},
+					Links: map[string]Link{
+						"GetUser": {
+							OperationID: "getUser",
+							Description: "the created user",
+							Parameters: map[string]interface{}{
+								"userId": "$response.body#/id",
+							},
+						},
+					},
+				},
+			},
+			expectedErr: nil,
+			expectedJSON: `{
+				"responses": {
+					"201": {
+						"description": "created",
+						"headers": {
+							"Location": {
+								"description": "the url of the created user",
+								"example": "/users/1",
+								"required": true,
+								"schema": {
+									"type": "string"
+								}
+							},
+							"X-Legacy": {
+								"deprecated": true,
+								"schema": {}
+							},
+							"X-RateLimit-Remaining": {
+								"schema": {
+									"type": "integer"
+								}
+							}
+						},
+						"links": {
+							"GetUser": {
+								"description": "the created user",
+								"operationId": "getUser",
+								"parameters": {
+									"userId": "$response.body#/id"
+								}
+							}
+						}
+					}
+				}
+			}`,
+		},
 	}
 
 	mux := mux.NewRouter()
@@ -1043,6 +1232,82 @@ func TestResolveParametersSchema(t *testing.T) {
 				"responses": null
 			}`,
 		},
+		{
+			name:      "query param with serialization options",
+			paramType: queryParamType,
+			paramsSchema: ParameterValue{
+				"filter": {
+					Schema: &Schema{
+						Value: map[string]string{},
+					},
+					Required: true,
+					Style:    openapi3.SerializationDeepObject,
+					Explode:  openapi3.Ptr(true),
+					Example:  map[string]string{"status": "active"},
+				},
+				"ids": {
+					Schema: &Schema{
+						Value: []int{},
+					},
+					Deprecated:      true,
+					Style:           openapi3.SerializationPipeDelimited,
+					Explode:         openapi3.Ptr(false),
+					AllowEmptyValue: true,
+					AllowReserved:   true,
+				},
+			},
+			expectedJSON: `{
+				"parameters": [{
+					"example": {"status": "active"},
+					"explode": true,
+					"in": "query",
+					"name": "filter",
+					"required": true,
+					"schema": {
+						"additionalProperties": {"type": "string"},
+						"type": "object"
+					},
+					"style": "deepObject"
+				}, {
+					"allowEmptyValue": true,
+					"allowReserved": true,
+					"deprecated": true,
+					"explode": false,
+					"in": "query",
+					"name": "ids",
+					"schema": {
+						"items": {"type": "integer"},
+						"type": "array"
+					},
+					"style": "pipeDelimited"
+				}],
+				"responses": null
+			}`,
+		},
+		{
+			name:      "path param is always required",
+			paramType: pathParamsType,
+			paramsSchema: ParameterValue{
+				"id": {
+					Schema: &Schema{
+						Value: "",
+					},
+					Style: openapi3.SerializationLabel,
+				},
+			},
+			expectedJSON: `{
+				"parameters": [{
+					"in": "path",
+					"name": "id",
+					"required": true,
+					"schema": {
+						"type": "string"
+					},
+					"style": "label"
+				}],
+				"responses": null
+			}`,
+		},
 		{
 			name:      "wrong param type",
 			paramType: "wrong",
@@ -1122,6 +1387,20 @@ func getBaseSwagger(t *testing.T) *openapi3.T {
 	}
 }
 
+func newTestRouter(t *testing.T, r *mux.Router, options Options) *TestRouter {
+	t.Helper()
+
+	options.Openapi = getBaseSwagger(t)
+	router, err := NewRouter(gorilla.NewRouter(r), options)
+	require.NoError(t, err)
+	return router
+}
+
+func okHandler(w http.ResponseWriter, req *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
 func TestGetPathParamsAutoComplete(t *testing.T) {
 	testCases := map[string]struct {
 		schemaDefinition Definitions
@@ -1169,6 +1448,35 @@ func TestGetPathParamsAutoComplete(t *testing.T) {
 				},
 			},
 		},
+		"with declared path params": {
+			schemaDefinition: Definitions{
+				PathParams: ParameterValue{
+					"bar": {
+						Schema: &Schema{Value: 0},
+					},
+				},
+			},
+			path: "/foo/{bar}/{baz}",
+			expected: ParameterValue{
+				"bar": {
+					Schema: &Schema{Value: 0},
+				},
+				"baz": {
+					Schema: &Schema{Value: ""},
+				},
+			},
+		},
+		"with declared path params not in the path": {
+			schemaDefinition: Definitions{
+				PathParams: ParameterValue{
+					"bar": {},
+				},
+			},
+			path: "/foo",
+			expected: ParameterValue{
+				"bar": {},
+			},
+		},
 	}
 
 	for name, test := range testCases {
@@ -1179,3 +1487,70 @@ func TestGetPathParamsAutoComplete(t *testing.T) {
 		})
 	}
 }
+
+func TestAddRoutePathParams(t *testing.T) {
+	t.Run("missing path params are autocompleted", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{PathPrefix: "/tenants/{tenantId}"})
+
+		_, err := router.AddRoute(http.MethodGet, "/users/{userId}/orders/{orderId}", okHandler, Definitions{
+			PathParams: ParameterValue{
+				"userId": {
+					Schema:      &Schema{Value: 0},
+					Description: "the user id",
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/path-params-autocomplete.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("unknown path params", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			definitions   Definitions
+			expectedError string
+		}{
+			{
+				name: "declared path param",
+				definitions: Definitions{
+					PathParams: ParameterValue{
+						"id": {},
+					},
+				},
+				expectedError: "path parameter id: parameter is not in the path /tenants/{tenantId}/users/{userId}",
+			},
+			{
+				name: "path param of the parameters struct",
+				definitions: Definitions{
+					Parameters: struct {
+						UserID string `path:"user_id"`
+					}{},
+				},
+				expectedError: "path parameter user_id: parameter is not in the path /tenants/{tenantId}/users/{userId}",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				router := newTestRouter(t, mux.NewRouter(), Options{PathPrefix: "/tenants/{tenantId}"})
+
+				_, err := router.AddRoute(http.MethodGet, "/users/{userId}", okHandler, test.definitions)
+				require.ErrorIs(t, err, ErrPathParams)
+				require.EqualError(t, err, fmt.Sprintf("%s: GET /tenants/{tenantId}/users/{userId}: %s", ErrPathParams, test.expectedError))
+			})
+		}
+	})
+}
@@ -0,0 +1,926 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	jsonSchemaTagName           = "jsonschema"
+	jsonSchemaExtrasTagName     = "jsonschema_extras"
+	jsonSchemaDefinitionsPrefix = "#/$defs/"
+	schemaComponentsPrefix      = "#/components/schemas/"
+	// mappedSchemaKey marks, in the reflected json schema, the schemas to
+	// replace with the one returned by a TypeMapper.
+	mappedSchemaKey = "x-gswagger-mapped-schema"
+)
+
+var (
+	packageQualifierRegexp    = regexp.MustCompile(`[\w\-./]+\.`)
+	invalidComponentNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+	schemaProviderType   = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+	enumProviderType     = reflect.TypeOf((*EnumProvider)(nil)).Elem()
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonMarshalerType    = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonSchemaCustomType = reflect.TypeOf((*interface{ JSONSchema() *jsonschema.Schema })(nil)).Elem()
+	// types implementing encoding.TextMarshaler already handled by the jsonschema lib
+	jsonSchemaFormatTypes = map[reflect.Type]bool{
+		reflect.TypeOf(time.Time{}): true,
+		reflect.TypeOf(net.IP{}):    true,
+	}
+)
+
+// SchemaProvider is implemented by the types which provide their own schema.
+// The schema is used instead of reflecting the type, wherever the type is used.
+// The method is called on the zero value of the type.
+type SchemaProvider interface {
+	OpenAPISchema() *openapi3.Schema
+}
+
+// EnumProvider is implemented by the types which have a fixed set of values,
+// e.g. a string type with a const block. Wherever the type is used, its schema
+// has the enum with the returned values. The method is called on the zero value
+// of the type.
+type EnumProvider interface {
+	EnumValues() []any
+}
+
+// EnumVarNamesProvider can be implemented by an EnumProvider to set the
+// x-enum-varnames extension, with the names of the constants of each value.
+// It is ignored if the names are not as many as the values.
+type EnumVarNamesProvider interface {
+	EnumVarNames() []string
+}
+
+// TypeMapper returns the schema to use for the given Go type, instead of
+// reflecting it. If it returns nil, the type is reflected.
+type TypeMapper func(t reflect.Type) *openapi3.Schema
+
+// TypeMappings contains the TypeMapper of each Go type. The keys must not be
+// pointer types: a pointer uses the mapping of the type it points to.
+type TypeMappings map[reflect.Type]TypeMapper
+
+// TypeSchema returns a TypeMapper which always returns the given schema.
+func TypeSchema(schema *openapi3.Schema) TypeMapper {
+	return func(reflect.Type) *openapi3.Schema {
+		s := *schema
+		return &s
+	}
+}
+
+// SchemaNamer returns the name used to register a named Go type
+// in the openapi components schemas.
+type SchemaNamer func(t reflect.Type) string
+
+// DefaultSchemaNamer is the SchemaNamer used when no custom one is set.
+// It uses the name of the Go type, without the package path. Generic types
+// are named joining the type name with the names of its type arguments
+// (e.g. Page[pkg.User] becomes Page_User).
+func DefaultSchemaNamer(t reflect.Type) string {
+	name := packageQualifierRegexp.ReplaceAllString(t.Name(), "")
+	return strings.Trim(invalidComponentNameChars.ReplaceAllString(name, "_"), "_")
+}
+
+// resolveSchema returns the openapi schema of the given Schema.
+func (r Router[_, _]) resolveSchema(schema Schema) (*openapi3.SchemaRef, error) {
+	if schema.OneOf == nil && schema.AnyOf == nil && schema.AllOf == nil {
+		if schema.Discriminator != nil {
+			return nil, fmt.Errorf("discriminator is supported only with oneOf, anyOf or allOf")
+		}
+		return r.getSchemaFromInterface(schema.Value, schema.AllowAdditionalProperties)
+	}
+	if schema.Value != nil {
+		return nil, fmt.Errorf("value must not be set with oneOf, anyOf or allOf")
+	}
+
+	composed := openapi3.NewSchema()
+	var err error
+	if composed.OneOf, err = r.getComponentSchemaRefs(schema.OneOf, schema.AllowAdditionalProperties); err != nil {
+		return nil, err
+	}
+	if composed.AnyOf, err = r.getComponentSchemaRefs(schema.AnyOf, schema.AllowAdditionalProperties); err != nil {
+		return nil, err
+	}
+	if composed.AllOf, err = r.getComponentSchemaRefs(schema.AllOf, schema.AllowAdditionalProperties); err != nil {
+		return nil, err
+	}
+
+	if schema.Discriminator != nil {
+		if schema.Discriminator.PropertyName == "" {
+			return nil, fmt.Errorf("discriminator property name is required")
+		}
+		composed.Discriminator = &openapi3.Discriminator{
+			PropertyName: schema.Discriminator.PropertyName,
+		}
+		values := make([]string, 0, len(schema.Discriminator.Mapping))
+		for value := range schema.Discriminator.Mapping {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			schemaRef, err := r.getComponentSchemaRef(schema.Discriminator.Mapping[value], schema.AllowAdditionalProperties)
+			if err != nil {
+				return nil, err
+			}
+			if schemaRef.Ref == "" {
+				return nil, fmt.Errorf("discriminator mapping %s: %T is not a named type", value, schema.Discriminator.Mapping[value])
+			}
+			if composed.Discriminator.Mapping == nil {
+				composed.Discriminator.Mapping = openapi3.StringMap[openapi3.MappingRef]{}
+			}
+			composed.Discriminator.Mapping[value] = openapi3.MappingRef(*schemaRef)
+		}
+	}
+
+	return openapi3.NewSchemaRef("", composed), nil
+}
+
+func (r Router[_, _]) getComponentSchemaRefs(values []interface{}, allowAdditionalProperties bool) (openapi3.SchemaRefs, error) {
+	if values == nil {
+		return nil, nil
+	}
+	schemaRefs := make(openapi3.SchemaRefs, 0, len(values))
+	for _, v := range values {
+		schemaRef, err := r.getComponentSchemaRef(v, allowAdditionalProperties)
+		if err != nil {
+			return nil, err
+		}
+		schemaRefs = append(schemaRefs, schemaRef)
+	}
+	return schemaRefs, nil
+}
+
+// getComponentSchemaRef returns the schema of the value and, if it is a named
+// type, adds it to the components schemas (also if UseSchemaComponents is not
+// set) and returns its reference.
+func (r Router[_, _]) getComponentSchemaRef(v interface{}, allowAdditionalProperties bool) (*openapi3.SchemaRef, error) {
+	schemaRef, err := r.getSchemaFromInterface(v, allowAdditionalProperties)
+	if err != nil || schemaRef.Ref != "" || v == nil {
+		return schemaRef, err
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := r.schemaName(t)
+	if name == "" {
+		return schemaRef, nil
+	}
+
+	components := r.components()
+	if components.Schemas == nil {
+		components.Schemas = openapi3.Schemas{}
+	}
+	if registered, ok := components.Schemas[name]; ok {
+		if r.schemaTypes[name] != t {
+			return nil, fmt.Errorf("schema component %s is already defined and it is not generated from type %s", name, t)
+		}
+		equal, err := equalSchemas(registered.Value, schemaRef.Value)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			return nil, fmt.Errorf("schema component %s is already defined with a different schema", name)
+		}
+	} else {
+		components.Schemas[name] = schemaRef
+		r.schemaTypes[name] = t
+	}
+
+	return openapi3.NewSchemaRef(schemaComponentsPrefix+name, components.Schemas[name].Value), nil
+}
+
+func (r Router[_, _]) getSchemaFromInterface(v interface{}, allowAdditionalProperties bool) (*openapi3.SchemaRef, error) {
+	if v == nil {
+		return openapi3.NewSchemaRef("", &openapi3.Schema{}), nil
+	}
+
+	// Recursive types can't be inlined, so they are always referenced.
+	useReferences := r.schemaComponents || isRecursiveType(reflect.TypeOf(v))
+	reflector := &jsonschema.Reflector{
+		DoNotReference:            !useReferences,
+		AllowAdditionalProperties: allowAdditionalProperties,
+		Anonymous:                 true,
+	}
+
+	namedTypes := map[string]reflect.Type{}
+	var namerErr error
+	registerName := func(name string, t reflect.Type) {
+		if other, ok := namedTypes[name]; ok && other != t && namerErr == nil {
+			namerErr = fmt.Errorf("types %s and %s have the same schema name %s", other, t, name)
+		}
+		namedTypes[name] = t
+	}
+	if useReferences {
+		reflector.Namer = func(t reflect.Type) string {
+			name := r.schemaName(t)
+			if name != "" {
+				registerName(name, t)
+			}
+			return name
+		}
+	}
+
+	mappedSchemas := map[string]*openapi3.Schema{}
+	// The named types whose schema is declared by the type mappings or by the
+	// type itself are not reflected, so they are added to the definitions here.
+	mappedDefinitions := jsonschema.Definitions{}
+	reflector.Mapper = func(t reflect.Type) *jsonschema.Schema {
+		name := ""
+		if useReferences {
+			name = r.mappedSchemaName(t)
+		}
+		if definition, ok := mappedDefinitions[name]; ok && namedTypes[name] == t {
+			return mappedSchemaRef(name, definition)
+		}
+
+		schema, declared := r.mapType(t)
+		if schema == nil {
+			return nil
+		}
+		id := strconv.Itoa(len(mappedSchemas))
+		mappedSchemas[id] = schema
+		// The placeholder has the type of the mapped schema, so the struct tags
+		// of the fields specific to that type are reflected too.
+		placeholder := &jsonschema.Schema{
+			Extras: map[string]any{mappedSchemaKey: id},
+		}
+		if types := schema.Type.Slice(); len(types) == 1 {
+			placeholder.Type = types[0]
+		}
+		if !declared || name == "" {
+			return placeholder
+		}
+		registerName(name, t)
+		mappedDefinitions[name] = placeholder
+		return mappedSchemaRef(name, placeholder)
+	}
+
+	jsonSchema := reflector.Reflect(v)
+	jsonSchema.Version = ""
+	if namerErr != nil {
+		return nil, namerErr
+	}
+	// Definitions are not valid in openapi3, which uses components. If components
+	// are disabled, only recursive definitions are kept and the others are inlined.
+	definitions := jsonSchema.Definitions
+	jsonSchema.Definitions = nil
+	if len(mappedDefinitions) > 0 {
+		if definitions == nil {
+			definitions = jsonschema.Definitions{}
+		}
+		maps.Copy(definitions, mappedDefinitions)
+	}
+	if r.validateTags {
+		if err := r.applyValidateTags(jsonSchema, definitions, reflect.TypeOf(v)); err != nil {
+			return nil, err
+		}
+	}
+	if err := setReadWriteOnly(jsonSchema, definitions, reflect.TypeOf(v)); err != nil {
+		return nil, err
+	}
+	if r.openapi31 {
+		if err := setNullablePointers(jsonSchema, definitions, reflect.TypeOf(v)); err != nil {
+			return nil, err
+		}
+	}
+	if !r.schemaComponents {
+		definitions = inlineDefinitions(jsonSchema, definitions)
+	}
+
+	for _, s := range append([]*jsonschema.Schema{jsonSchema}, definitionsList(definitions)...) {
+		walkJSONSchema(s, func(s *jsonschema.Schema) { unsetMappedRefType(s, definitions) })
+		walkJSONSchema(s, setNullableKeyword)
+		walkJSONSchema(s, wrapRefWithSiblings)
+	}
+
+	schema, err := jsonSchemaToSchemaRef(jsonSchema, mappedSchemas)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(definitions) > 0 {
+		if err := r.addSchemaComponents(definitions, namedTypes, mappedSchemas, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+// mapType returns the schema of the type from the type mappings or, if not
+// set, from the type itself if it is a SchemaProvider or an EnumProvider.
+// Types which are marshalled as text are mapped to string schemas, and
+// interfaces to empty schemas. declared is true if the schema is declared by
+// the type mappings or by the type itself.
+func (r Router[_, _]) mapType(t reflect.Type) (schema *openapi3.Schema, declared bool) {
+	if mapper, ok := r.typeMappings[t]; ok {
+		if schema := mapper(t); schema != nil {
+			return schema, true
+		}
+	}
+	// The fields with interface type accept any value, and have no value to
+	// call the SchemaProvider or EnumProvider methods on.
+	if t.Kind() == reflect.Interface {
+		return openapi3.NewSchema(), false
+	}
+	if implements(t, schemaProviderType) {
+		if schema := reflect.New(t).Interface().(SchemaProvider).OpenAPISchema(); schema != nil {
+			return schema, true
+		}
+	}
+	if implements(t, enumProviderType) {
+		if schema := enumSchema(t); schema != nil {
+			return schema, true
+		}
+	}
+	if jsonSchemaFormatTypes[t] || implements(t, jsonMarshalerType) || implements(t, jsonSchemaCustomType) {
+		return nil, false
+	}
+	if implements(t, textMarshalerType) {
+		return openapi3.NewStringSchema(), false
+	}
+	return nil, false
+}
+
+// mappedSchemaRef returns the reference to the definition of a mapped type.
+// The reference has the type of the definition, so the struct tags of the
+// fields specific to that type are reflected too, until unsetMappedRefType
+// removes it.
+func mappedSchemaRef(name string, definition *jsonschema.Schema) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Ref:  jsonSchemaDefinitionsPrefix + name,
+		Type: definition.Type,
+	}
+}
+
+// unsetMappedRefType removes the type set by mappedSchemaRef beside the
+// references to the mapped types definitions.
+func unsetMappedRefType(schema *jsonschema.Schema, definitions jsonschema.Definitions) {
+	name, ok := strings.CutPrefix(schema.Ref, jsonSchemaDefinitionsPrefix)
+	if !ok {
+		return
+	}
+	if definition, ok := definitions[name]; ok && definition.Extras[mappedSchemaKey] != nil && schema.Type == definition.Type {
+		schema.Type = ""
+	}
+}
+
+// enumSchema returns the schema of the EnumProvider type, with the type
+// given by its kind.
+func enumSchema(t reflect.Type) *openapi3.Schema {
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	value := reflect.New(t).Interface()
+	enumProvider, ok := value.(EnumProvider)
+	if !ok {
+		return nil
+	}
+	values := enumProvider.EnumValues()
+	if len(values) == 0 {
+		return nil
+	}
+
+	var schema *openapi3.Schema
+	switch t.Kind() {
+	case reflect.String:
+		schema = openapi3.NewStringSchema()
+	case reflect.Bool:
+		schema = openapi3.NewBoolSchema()
+	case reflect.Float32, reflect.Float64:
+		schema = openapi3.NewFloat64Schema()
+	default:
+		if !isNumberKind(t.Kind()) {
+			return nil
+		}
+		schema = openapi3.NewIntegerSchema()
+	}
+	schema.Enum = values
+
+	if provider, ok := value.(EnumVarNamesProvider); ok {
+		if names := provider.EnumVarNames(); len(names) == len(values) {
+			schema.Extensions = map[string]any{"x-enum-varnames": names}
+		}
+	}
+	return schema
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// schemaName returns the component name of the type. Only named structs,
+// slices, arrays and maps are added to the components.
+func (r Router[_, _]) schemaName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return r.mappedSchemaName(t)
+	}
+	return ""
+}
+
+// mappedSchemaName returns the component name of a mapped type. The named
+// types of any kind, whose schema is declared by the type mappings or by the
+// type itself, are added to the components.
+func (r Router[_, _]) mappedSchemaName(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ""
+	}
+	if r.schemaNamer != nil {
+		if name := r.schemaNamer(t); name != "" {
+			return name
+		}
+	}
+	return DefaultSchemaNamer(t)
+}
+
+// addSchemaComponents registers the reflected definitions in the openapi
+// components and links all the references of the schema to them.
+func (r Router[_, _]) addSchemaComponents(definitions jsonschema.Definitions, namedTypes map[string]reflect.Type, mappedSchemas map[string]*openapi3.Schema, schema *openapi3.SchemaRef) error {
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reflected := make(openapi3.Schemas, len(definitions))
+	for _, name := range names {
+		definition, err := jsonSchemaToSchemaRef(definitions[name], mappedSchemas)
+		if err != nil {
+			return err
+		}
+		reflected[name] = definition
+	}
+
+	// The schemas are linked to the registered components and to the new ones,
+	// which are added to the components only if all of them are valid.
+	components := r.components()
+	schemas := maps.Clone(components.Schemas)
+	if schemas == nil {
+		schemas = openapi3.Schemas{}
+	}
+	alreadyRegistered := []string{}
+	for _, name := range names {
+		if _, ok := schemas[name]; ok {
+			if registeredType := r.schemaTypes[name]; registeredType != namedTypes[name] {
+				return fmt.Errorf("schema component %s is already defined and it is not generated from type %s", name, namedTypes[name])
+			}
+			alreadyRegistered = append(alreadyRegistered, name)
+			continue
+		}
+		schemas[name] = reflected[name]
+	}
+
+	visited := map[*openapi3.Schema]struct{}{}
+	for _, name := range names {
+		linkSchemaRefs(reflected[name], schemas, visited)
+	}
+	linkSchemaRefs(schema, schemas, visited)
+
+	for _, name := range alreadyRegistered {
+		equal, err := equalSchemas(schemas[name].Value, reflected[name].Value)
+		if err != nil {
+			return err
+		}
+		if !equal {
+			return fmt.Errorf("schema component %s is already defined with a different schema", name)
+		}
+	}
+
+	if components.Schemas == nil {
+		components.Schemas = openapi3.Schemas{}
+	}
+	for _, name := range names {
+		if _, ok := components.Schemas[name]; !ok {
+			components.Schemas[name] = reflected[name]
+			r.schemaTypes[name] = namedTypes[name]
+		}
+	}
+	return nil
+}
+
+// linkSchemaRefs replaces the json schema definitions references with the
+// components ones, and set the referenced schema as value of the reference.
+func linkSchemaRefs(schemaRef *openapi3.SchemaRef, schemas openapi3.Schemas, visited map[*openapi3.Schema]struct{}) {
+	if schemaRef == nil {
+		return
+	}
+	if name, ok := strings.CutPrefix(schemaRef.Ref, jsonSchemaDefinitionsPrefix); ok {
+		schemaRef.Ref = schemaComponentsPrefix + name
+		if component, ok := schemas[name]; ok {
+			schemaRef.Value = component.Value
+		}
+	}
+
+	schema := schemaRef.Value
+	if schema == nil {
+		return
+	}
+	if _, ok := visited[schema]; ok {
+		return
+	}
+	visited[schema] = struct{}{}
+
+	for _, ref := range schema.OneOf {
+		linkSchemaRefs(ref, schemas, visited)
+	}
+	for _, ref := range schema.AnyOf {
+		linkSchemaRefs(ref, schemas, visited)
+	}
+	for _, ref := range schema.AllOf {
+		linkSchemaRefs(ref, schemas, visited)
+	}
+	for _, ref := range schema.Properties {
+		linkSchemaRefs(ref, schemas, visited)
+	}
+	linkSchemaRefs(schema.Not, schemas, visited)
+	linkSchemaRefs(schema.Items, schemas, visited)
+	linkSchemaRefs(schema.AdditionalProperties.Schema, schemas, visited)
+}
+
+// isRecursiveType returns true if the type contains itself, directly or
+// through other types.
+func isRecursiveType(t reflect.Type) bool {
+	return hasTypeCycle(t, map[reflect.Type]bool{})
+}
+
+func hasTypeCycle(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if inProgress, ok := visiting[t]; ok {
+		return inProgress
+	}
+	visiting[t] = true
+	defer func() { visiting[t] = false }()
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return hasTypeCycle(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if field.Tag.Get("json") == "-" {
+				continue
+			}
+			if hasTypeCycle(field.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inlineDefinitions replaces, in the schema and in the definitions, the
+// references to the definitions which are not recursive with the referenced
+// schema. It returns the recursive definitions, which must still be referenced.
+func inlineDefinitions(schema *jsonschema.Schema, definitions jsonschema.Definitions) jsonschema.Definitions {
+	references := map[string][]string{}
+	for name, definition := range definitions {
+		walkJSONSchema(definition, func(s *jsonschema.Schema) {
+			if ref, ok := strings.CutPrefix(s.Ref, jsonSchemaDefinitionsPrefix); ok {
+				references[name] = append(references[name], ref)
+			}
+		})
+	}
+
+	recursive := jsonschema.Definitions{}
+	for name, definition := range definitions {
+		if isReachable(references, name, name, map[string]bool{}) {
+			recursive[name] = definition
+		}
+	}
+
+	inline := func(s *jsonschema.Schema) {
+		name, ok := strings.CutPrefix(s.Ref, jsonSchemaDefinitionsPrefix)
+		if !ok {
+			return
+		}
+		if _, ok := recursive[name]; ok {
+			return
+		}
+		definition, ok := definitions[name]
+		if !ok {
+			return
+		}
+		siblings := *s
+		siblings.Ref = ""
+		*s = *definition
+		overrideJSONSchema(s, &siblings)
+	}
+	walkJSONSchema(schema, inline)
+	for _, definition := range recursive {
+		walkJSONSchema(definition, inline)
+	}
+
+	return recursive
+}
+
+func isReachable(references map[string][]string, from, to string, visited map[string]bool) bool {
+	for _, ref := range references[from] {
+		if ref == to {
+			return true
+		}
+		if visited[ref] {
+			continue
+		}
+		visited[ref] = true
+		if isReachable(references, ref, to, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideJSONSchema sets in the schema all the not empty fields of the override.
+func overrideJSONSchema(schema *jsonschema.Schema, override *jsonschema.Schema) {
+	schemaValue := reflect.ValueOf(schema).Elem()
+	overrideValue := reflect.ValueOf(override).Elem()
+	for i := 0; i < overrideValue.NumField(); i++ {
+		field := overrideValue.Field(i)
+		if !overrideValue.Type().Field(i).IsExported() || field.IsZero() {
+			continue
+		}
+		schemaValue.Field(i).Set(field)
+	}
+	if len(override.Extras) > 0 {
+		extras := make(map[string]any, len(override.Extras))
+		for k, v := range schema.Extras {
+			extras[k] = v
+		}
+		for k, v := range override.Extras {
+			extras[k] = v
+		}
+		schema.Extras = extras
+	}
+}
+
+// walkJSONSchema calls fn on the schema and on all its sub schemas.
+func walkJSONSchema(schema *jsonschema.Schema, fn func(schema *jsonschema.Schema)) {
+	if schema == nil {
+		return
+	}
+	fn(schema)
+
+	for _, s := range schema.AllOf {
+		walkJSONSchema(s, fn)
+	}
+	for _, s := range schema.AnyOf {
+		walkJSONSchema(s, fn)
+	}
+	for _, s := range schema.OneOf {
+		walkJSONSchema(s, fn)
+	}
+	if schema.Properties != nil {
+		for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			walkJSONSchema(pair.Value, fn)
+		}
+	}
+	for _, s := range schema.PatternProperties {
+		walkJSONSchema(s, fn)
+	}
+	walkJSONSchema(schema.Not, fn)
+	walkJSONSchema(schema.Items, fn)
+	walkJSONSchema(schema.AdditionalProperties, fn)
+}
+
+// structFieldFunc is called by walkStructFields with the schema of a struct,
+// the name and the schema of one of its properties and the field reflected
+// as that property.
+type structFieldFunc func(parent *jsonschema.Schema, name string, property *jsonschema.Schema, field reflect.StructField) error
+
+// walkStructFields walks the Go type and its reflected json schema in parallel,
+// calling fn for each struct field found in the type or in its elements.
+// The references to the definitions are followed.
+func walkStructFields(schema *jsonschema.Schema, t reflect.Type, definitions jsonschema.Definitions, fn structFieldFunc) error {
+	return walkStructFieldsVisiting(schema, t, definitions, fn, map[reflect.Type]bool{})
+}
+
+func walkStructFieldsVisiting(schema *jsonschema.Schema, t reflect.Type, definitions jsonschema.Definitions, fn structFieldFunc, visited map[reflect.Type]bool) error {
+	if schema == nil || t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	schema = resolveDefinition(schema, definitions)
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return walkStructFieldsVisiting(schema.Items, t.Elem(), definitions, fn, visited)
+	case reflect.Map:
+		return walkStructFieldsVisiting(schema.AdditionalProperties, t.Elem(), definitions, fn, visited)
+	case reflect.Struct:
+		if visited[t] || schema.Properties == nil {
+			return nil
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, embedded := jsonFieldName(field)
+			if embedded {
+				if err := walkStructFieldsVisiting(schema, field.Type, definitions, fn, visited); err != nil {
+					return err
+				}
+				continue
+			}
+			if name == "" {
+				continue
+			}
+			property, ok := schema.Properties.Get(name)
+			if !ok {
+				continue
+			}
+			if err := fn(schema, name, property, field); err != nil {
+				return err
+			}
+			if err := walkStructFieldsVisiting(nullableSchema(property), field.Type, definitions, fn, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveDefinition returns the definition referenced by the schema, or the
+// schema itself if it is not a reference.
+func resolveDefinition(schema *jsonschema.Schema, definitions jsonschema.Definitions) *jsonschema.Schema {
+	if name, ok := strings.CutPrefix(schema.Ref, jsonSchemaDefinitionsPrefix); ok {
+		if definition, ok := definitions[name]; ok {
+			return definition
+		}
+	}
+	return schema
+}
+
+// nullableSchema returns the schema wrapped by the jsonschema nullable tag.
+func nullableSchema(schema *jsonschema.Schema) *jsonschema.Schema {
+	if len(schema.OneOf) == 2 && schema.OneOf[1].Type == "null" {
+		return schema.OneOf[0]
+	}
+	return schema
+}
+
+// setNullableKeyword replaces the oneOf with the null type, generated by the
+// jsonschema nullable tag, with the openapi nullable keyword.
+func setNullableKeyword(schema *jsonschema.Schema) {
+	nonNullSchema := nullableSchema(schema)
+	if nonNullSchema == schema {
+		return
+	}
+	siblings := *schema
+	siblings.OneOf = nil
+	*schema = *nonNullSchema
+	schema.Extras = maps.Clone(schema.Extras)
+	overrideJSONSchema(schema, &siblings)
+	setExtra(schema, "nullable", true)
+}
+
+// wrapRefWithSiblings moves a $ref with sibling keywords (e.g. the title or
+// the description of a struct field) inside an allOf, since in openapi 3.0
+// the siblings of a $ref are ignored.
+func wrapRefWithSiblings(schema *jsonschema.Schema) {
+	if schema.Ref == "" {
+		return
+	}
+	siblings := *schema
+	siblings.Ref = ""
+	if reflect.DeepEqual(siblings, jsonschema.Schema{}) {
+		return
+	}
+	siblings.AllOf = append([]*jsonschema.Schema{{Ref: schema.Ref}}, siblings.AllOf...)
+	*schema = siblings
+}
+
+func definitionsList(definitions jsonschema.Definitions) []*jsonschema.Schema {
+	list := make([]*jsonschema.Schema, 0, len(definitions))
+	for _, definition := range definitions {
+		list = append(list, definition)
+	}
+	return list
+}
+
+func jsonSchemaToSchemaRef(jsonSchema *jsonschema.Schema, mappedSchemas map[string]*openapi3.Schema) (*openapi3.SchemaRef, error) {
+	data, err := jsonSchema.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if len(mappedSchemas) > 0 {
+		if data, err = replaceMappedSchemas(data, mappedSchemas); err != nil {
+			return nil, err
+		}
+	}
+
+	schema := &openapi3.SchemaRef{}
+	if err := schema.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	if schema.Ref == "" && schema.Value == nil {
+		schema.Value = openapi3.NewSchema()
+	}
+
+	return schema, nil
+}
+
+// replaceMappedSchemas replaces the placeholders set for the mapped types
+// with their schema. The other keywords of the placeholder (e.g. the ones set
+// by the struct tags) override the ones of the mapped schema.
+func replaceMappedSchemas(data []byte, mappedSchemas map[string]*openapi3.Schema) ([]byte, error) {
+	value, err := unmarshalJSONValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var replace func(value any) (any, error)
+	replace = func(value any) (any, error) {
+		switch v := value.(type) {
+		case map[string]any:
+			for key, item := range v {
+				replaced, err := replace(item)
+				if err != nil {
+					return nil, err
+				}
+				v[key] = replaced
+			}
+			id, ok := v[mappedSchemaKey].(string)
+			if !ok {
+				return v, nil
+			}
+			mappedData, err := json.Marshal(mappedSchemas[id])
+			if err != nil {
+				return nil, err
+			}
+			mapped, err := unmarshalJSONValue(mappedData)
+			if err != nil {
+				return nil, err
+			}
+			mappedSchema := mapped.(map[string]any)
+			delete(v, mappedSchemaKey)
+			for key, item := range v {
+				mappedSchema[key] = item
+			}
+			return mappedSchema, nil
+		case []any:
+			for i, item := range v {
+				replaced, err := replace(item)
+				if err != nil {
+					return nil, err
+				}
+				v[i] = replaced
+			}
+		}
+		return value, nil
+	}
+
+	replaced, err := replace(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(replaced)
+}
+
+func unmarshalJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func equalSchemas(a, b *openapi3.Schema) (bool, error) {
+	aData, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bData, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(aData, bData), nil
+}
@@ -0,0 +1,648 @@
+package swagger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type componentAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city,omitempty"`
+}
+
+type componentUser struct {
+	Name     string            `json:"name"`
+	Address  componentAddress  `json:"address" jsonschema:"description=the main address"`
+	Previous *componentAddress `json:"previous,omitempty"`
+}
+
+type componentPage[T any] struct {
+	Items []T `json:"items"`
+}
+
+type componentInvoice struct {
+	Price   providedPrice   `json:"price"`
+	Status  enumOrderStatus `json:"status" jsonschema:"maxLength=10"`
+	Order   mappedOrder     `json:"order"`
+	Problem *ProblemDetails `json:"problem,omitempty"`
+}
+
+func TestSchemaComponents(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      Options
+		routes       func(t *testing.T, router *TestRouter)
+		fixturesPath string
+	}{
+		{
+			name: "named types are added to components",
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentUser{}},
+						},
+					},
+					Responses: map[int]ContentValue{
+						201: {
+							Content: Content{
+								jsonType: {Value: &componentUser{}},
+							},
+						},
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+					Responses: map[int]ContentValue{
+						200: {
+							Content: Content{
+								jsonType: {Value: componentPage[componentUser]{}},
+							},
+						},
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodGet, "/addresses", okHandler, Definitions{
+					Querystring: ParameterValue{
+						"filter": {
+							Content: Content{
+								jsonType: {Value: componentAddress{}},
+							},
+						},
+					},
+					Responses: map[int]ContentValue{
+						200: {
+							Content: Content{
+								jsonType: {Value: []componentAddress{}},
+							},
+						},
+					},
+				})
+				require.NoError(t, err)
+			},
+			fixturesPath: "testdata/components.json",
+		},
+		{
+			name: "with custom schema namer",
+			options: Options{
+				SchemaNamer: func(t reflect.Type) string {
+					return "Custom" + DefaultSchemaNamer(t)
+				},
+			},
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentUser{}},
+						},
+					},
+				})
+				require.NoError(t, err)
+			},
+			fixturesPath: "testdata/components-custom-namer.json",
+		},
+		{
+			name: "named mapped types are added to components",
+			options: Options{
+				TypeMappings: TypeMappings{
+					reflect.TypeOf(mappedID{}): TypeSchema(openapi3.NewStringSchema().WithFormat("uuid")),
+				},
+			},
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/invoices", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentInvoice{}},
+						},
+					},
+					Responses: map[int]ContentValue{
+						201: {
+							Content: Content{
+								jsonType: {Value: &providedMoney{}},
+							},
+						},
+						400: {
+							Content: Content{
+								jsonType: {Value: &ProblemDetails{}},
+							},
+						},
+					},
+				})
+				require.NoError(t, err)
+			},
+			fixturesPath: "testdata/components-mapped.json",
+		},
+		{
+			name: "fails if the same name is used by different types",
+			options: Options{
+				SchemaNamer: func(t reflect.Type) string {
+					return "Same"
+				},
+			},
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentUser{}},
+						},
+					},
+				})
+				require.EqualError(t, err, "errors generating request body schema: POST /users: request body: types swagger.componentUser and swagger.componentAddress have the same schema name Same")
+			},
+			fixturesPath: "testdata/empty.json",
+		},
+		{
+			name: "fails if the same type is used with different additional properties",
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/addresses", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentAddress{}},
+						},
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodPut, "/addresses", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentAddress{}, AllowAdditionalProperties: true},
+						},
+					},
+				})
+				require.EqualError(t, err, "errors generating request body schema: PUT /addresses: request body: schema component componentAddress is already defined with a different schema")
+			},
+			fixturesPath: "testdata/components-address.json",
+		},
+		{
+			name: "fails without adding the new components if a registered one is different",
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/addresses", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentAddress{}},
+						},
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentPage[componentUser]{}, AllowAdditionalProperties: true},
+						},
+					},
+				})
+				require.EqualError(t, err, "errors generating request body schema: POST /users: request body: schema component componentAddress is already defined with a different schema")
+			},
+			fixturesPath: "testdata/components-address.json",
+		},
+		{
+			name: "fails without adding the components of an invalid route",
+			routes: func(t *testing.T, router *TestRouter) {
+				_, err := router.AddRoute(http.MethodPost, "/addresses", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentAddress{}},
+						},
+					},
+				})
+				require.NoError(t, err)
+
+				_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentPage[componentUser]{}},
+						},
+					},
+					Responses: map[int]ContentValue{
+						http.StatusNotFound: {Ref: "NotFound"},
+					},
+				})
+				require.ErrorIs(t, err, ErrResponses)
+
+				_, err = router.AddRoute(http.MethodPost, "/addresses", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: componentPage[componentUser]{}},
+						},
+					},
+				})
+				require.ErrorIs(t, err, ErrDuplicateRoute)
+			},
+			fixturesPath: "testdata/components-address.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+
+			options := test.options
+			options.Context = context.Background()
+			options.UseSchemaComponents = true
+			router := newTestRouter(t, r, options)
+
+			test.routes(t, router)
+
+			err := router.GenerateAndExposeOpenapi()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			body := readBody(t, w.Result().Body)
+			expected, err := os.ReadFile(test.fixturesPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+		})
+	}
+}
+
+func TestDefaultSchemaNamer(t *testing.T) {
+	require.Equal(t, "componentUser", DefaultSchemaNamer(reflect.TypeOf(componentUser{})))
+	require.Equal(t, "componentPage_componentUser", DefaultSchemaNamer(reflect.TypeOf(componentPage[componentUser]{})))
+	require.Equal(t, "componentPage_componentPage_componentUser", DefaultSchemaNamer(reflect.TypeOf(componentPage[componentPage[componentUser]]{})))
+}
+
+type recursiveCategory struct {
+	Name     string              `json:"name"`
+	Children []recursiveCategory `json:"children,omitempty"`
+}
+
+type recursiveThread struct {
+	Title    string             `json:"title"`
+	Comments []recursiveComment `json:"comments"`
+}
+
+type recursiveComment struct {
+	Text    string           `json:"text"`
+	Author  componentAddress `json:"author"`
+	Replies *recursiveThread `json:"replies,omitempty" jsonschema:"description=the replies to the comment"`
+}
+
+func TestRecursiveSchemas(t *testing.T) {
+	routes := func(t *testing.T, router *TestRouter) {
+		_, err := router.AddRoute(http.MethodGet, "/categories", okHandler, Definitions{
+			Responses: map[int]ContentValue{
+				200: {
+					Content: Content{
+						jsonType: {Value: []recursiveCategory{}},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodPost, "/threads", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: &recursiveThread{}},
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name                string
+		useSchemaComponents bool
+		fixturesPath        string
+	}{
+		{
+			name:         "only recursive types are added to components",
+			fixturesPath: "testdata/recursive.json",
+		},
+		{
+			name:                "with schema components",
+			useSchemaComponents: true,
+			fixturesPath:        "testdata/recursive-components.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			router := newTestRouter(t, r, Options{
+				UseSchemaComponents: test.useSchemaComponents,
+			})
+
+			routes(t, router)
+
+			err := router.GenerateAndExposeOpenapi()
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			body := readBody(t, w.Result().Body)
+			expected, err := os.ReadFile(test.fixturesPath)
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+		})
+	}
+}
+
+func TestIsRecursiveType(t *testing.T) {
+	type selfPointer struct {
+		Next *selfPointer `json:"next"`
+	}
+	type ignoredRecursion struct {
+		Next *ignoredRecursion `json:"-"`
+	}
+
+	require.False(t, isRecursiveType(reflect.TypeOf(componentUser{})))
+	require.False(t, isRecursiveType(reflect.TypeOf(ignoredRecursion{})))
+	require.True(t, isRecursiveType(reflect.TypeOf(selfPointer{})))
+	require.True(t, isRecursiveType(reflect.TypeOf([]recursiveCategory{})))
+	require.True(t, isRecursiveType(reflect.TypeOf(map[string]*recursiveComment{})))
+}
+
+type mappedID [16]byte
+
+func (id mappedID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%x", id[:])), nil
+}
+
+type mappedDecimal struct {
+	value int64
+	exp   int32
+}
+
+type mappedOrder struct {
+	ID       mappedID       `json:"id"`
+	Amount   mappedDecimal  `json:"amount" jsonschema:"description=the order amount"`
+	Timeout  time.Duration  `json:"timeout"`
+	Address  netip.Addr     `json:"address"`
+	Previous *mappedID      `json:"previous,omitempty"`
+	Related  []mappedID     `json:"related,omitempty"`
+	Created  time.Time      `json:"created"`
+	Tags     map[string]any `json:"tags,omitempty"`
+}
+
+func TestTypeMappings(t *testing.T) {
+	r := mux.NewRouter()
+	router := newTestRouter(t, r, Options{
+		TypeMappings: TypeMappings{
+			reflect.TypeOf(mappedID{}): TypeSchema(&openapi3.Schema{
+				Type:   &openapi3.Types{openapi3.TypeString},
+				Format: "uuid",
+			}),
+			reflect.TypeOf(mappedDecimal{}): func(t reflect.Type) *openapi3.Schema {
+				return openapi3.NewStringSchema().WithPattern(`^-?\d+(\.\d+)?$`)
+			},
+			reflect.TypeOf(time.Duration(0)): TypeSchema(openapi3.NewStringSchema().WithFormat("duration")),
+		},
+	})
+
+	_, err := router.AddRoute(http.MethodPost, "/orders", okHandler, Definitions{
+		RequestBody: &ContentValue{
+			Content: Content{
+				jsonType: {Value: mappedOrder{}},
+			},
+		},
+		Querystring: ParameterValue{
+			"id": {
+				Schema: &Schema{Value: mappedID{}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	err = router.GenerateAndExposeOpenapi()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	body := readBody(t, w.Result().Body)
+	expected, err := os.ReadFile("testdata/type-mappings.json")
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+}
+
+type providedCurrency string
+
+func (providedCurrency) OpenAPISchema() *openapi3.Schema {
+	return openapi3.NewStringSchema().WithEnum("EUR", "USD").WithMinLength(3).WithMaxLength(3)
+}
+
+type providedMoney struct {
+	amount   int64
+	currency providedCurrency
+}
+
+func (*providedMoney) OpenAPISchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("amount", openapi3.NewInt64Schema()).
+		WithProperty("currency", providedCurrency("").OpenAPISchema())
+}
+
+type providedPrice struct {
+	Money    providedMoney    `json:"money"`
+	Currency providedCurrency `json:"currency" jsonschema:"description=currency of the price"`
+	Discount *providedMoney   `json:"discount,omitempty"`
+}
+
+func TestSchemaProvider(t *testing.T) {
+	r := mux.NewRouter()
+	router := newTestRouter(t, r, Options{})
+
+	_, err := router.AddRoute(http.MethodGet, "/prices/{currency}", okHandler, Definitions{
+		PathParams: ParameterValue{
+			"currency": {
+				Schema: &Schema{Value: providedCurrency("")},
+			},
+		},
+		Responses: map[int]ContentValue{
+			200: {
+				Content: Content{
+					jsonType: {Value: providedPrice{}},
+				},
+			},
+			204: {
+				Content: Content{
+					jsonType: {Value: &providedMoney{}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	err = router.GenerateAndExposeOpenapi()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	body := readBody(t, w.Result().Body)
+	expected, err := os.ReadFile("testdata/schema-provider.json")
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+}
+
+func TestSchemaProviderInterfaceField(t *testing.T) {
+	router := newTestRouter(t, mux.NewRouter(), Options{})
+
+	type withProvider struct {
+		Provider SchemaProvider `json:"provider"`
+	}
+	schema, err := router.getSchemaFromInterface(withProvider{}, false)
+	require.NoError(t, err)
+	require.Equal(t, &openapi3.Schema{}, schema.Value.Properties["provider"].Value)
+}
+
+type enumOrderStatus string
+
+const (
+	enumOrderStatusPending enumOrderStatus = "pending"
+	enumOrderStatusShipped enumOrderStatus = "shipped"
+)
+
+func (enumOrderStatus) EnumValues() []any {
+	return []any{enumOrderStatusPending, enumOrderStatusShipped}
+}
+
+func (enumOrderStatus) EnumVarNames() []string {
+	return []string{"OrderStatusPending", "OrderStatusShipped"}
+}
+
+type enumPriority int
+
+func (*enumPriority) EnumValues() []any {
+	return []any{1, 2, 3}
+}
+
+type enumOrder struct {
+	Status   enumOrderStatus   `json:"status" jsonschema:"description=the order status"`
+	History  []enumOrderStatus `json:"history,omitempty"`
+	Priority *enumPriority     `json:"priority,omitempty"`
+}
+
+func TestEnumValues(t *testing.T) {
+	r := mux.NewRouter()
+	router := newTestRouter(t, r, Options{})
+
+	_, err := router.AddRoute(http.MethodGet, "/orders/{status}", okHandler, Definitions{
+		PathParams: ParameterValue{
+			"status": {
+				Schema: &Schema{Value: enumOrderStatus("")},
+			},
+		},
+		Querystring: ParameterValue{
+			"priority": {
+				Schema: &Schema{Value: enumPriority(0)},
+			},
+		},
+		Responses: map[int]ContentValue{
+			200: {
+				Content: Content{
+					jsonType: {Value: []enumOrder{}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	err = router.GenerateAndExposeOpenapi()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	body := readBody(t, w.Result().Body)
+	expected, err := os.ReadFile("testdata/enum.json")
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+}
+
+func TestEnumProviderInterfaceField(t *testing.T) {
+	router := newTestRouter(t, mux.NewRouter(), Options{})
+
+	type withEnumProvider struct {
+		Status EnumProvider `json:"status"`
+	}
+	schema, err := router.getSchemaFromInterface(withEnumProvider{}, false)
+	require.NoError(t, err)
+	require.Equal(t, &openapi3.Schema{}, schema.Value.Properties["status"].Value)
+	require.Nil(t, enumSchema(reflect.TypeOf((*EnumProvider)(nil)).Elem()))
+}
+
+func TestResolveSchema(t *testing.T) {
+	router := newTestRouter(t, mux.NewRouter(), Options{})
+
+	tests := []struct {
+		name        string
+		schema      Schema
+		expectedErr string
+	}{
+		{
+			name: "value with oneOf",
+			schema: Schema{
+				Value: componentUser{},
+				OneOf: []interface{}{componentAddress{}},
+			},
+			expectedErr: "value must not be set with oneOf, anyOf or allOf",
+		},
+		{
+			name: "discriminator without composition",
+			schema: Schema{
+				Value:         componentUser{},
+				Discriminator: &Discriminator{PropertyName: "kind"},
+			},
+			expectedErr: "discriminator is supported only with oneOf, anyOf or allOf",
+		},
+		{
+			name: "discriminator without property name",
+			schema: Schema{
+				OneOf:         []interface{}{componentUser{}, componentAddress{}},
+				Discriminator: &Discriminator{},
+			},
+			expectedErr: "discriminator property name is required",
+		},
+		{
+			name: "discriminator mapping with not named type",
+			schema: Schema{
+				OneOf: []interface{}{componentUser{}, componentAddress{}},
+				Discriminator: &Discriminator{
+					PropertyName: "kind",
+					Mapping: map[string]interface{}{
+						"user": struct{}{},
+					},
+				},
+			},
+			expectedErr: "discriminator mapping user: struct {} is not a named type",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := router.resolveSchema(test.schema)
+			require.Nil(t, schema)
+			require.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
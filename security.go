@@ -0,0 +1,232 @@
+package swagger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/security"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	httpSecuritySchemeType          = "http"
+	apiKeySecuritySchemeType        = "apiKey"
+	oauth2SecuritySchemeType        = "oauth2"
+	openIDConnectSecuritySchemeType = "openIdConnect"
+)
+
+// OAuthFlow is the configuration of an OAuth2 flow. Scopes maps the name of
+// each scope to its description.
+type OAuthFlow struct {
+	AuthorizationURL string
+	TokenURL         string
+	RefreshURL       string
+	Scopes           map[string]string
+}
+
+// OAuthFlows contains the configuration of the supported OAuth2 flows.
+type OAuthFlows struct {
+	Implicit          *OAuthFlow
+	Password          *OAuthFlow
+	ClientCredentials *OAuthFlow
+	AuthorizationCode *OAuthFlow
+}
+
+// BearerSecurityScheme returns the http bearer security scheme. The bearer
+// format (e.g. JWT) is optional.
+func BearerSecurityScheme(bearerFormat string) *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type:         httpSecuritySchemeType,
+		Scheme:       "bearer",
+		BearerFormat: bearerFormat,
+	}
+}
+
+// BasicSecurityScheme returns the http basic security scheme.
+func BasicSecurityScheme() *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type:   httpSecuritySchemeType,
+		Scheme: "basic",
+	}
+}
+
+// APIKeySecurityScheme returns the security scheme of the api key with the
+// given name, passed in the header, in the query or in a cookie.
+func APIKeySecurityScheme(in string, name string) *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type: apiKeySecuritySchemeType,
+		In:   in,
+		Name: name,
+	}
+}
+
+// OAuth2SecurityScheme returns the OAuth2 security scheme with the given flows.
+func OAuth2SecurityScheme(flows OAuthFlows) *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type: oauth2SecuritySchemeType,
+		Flows: &openapi3.OAuthFlows{
+			Implicit:          flows.Implicit.oauthFlow(),
+			Password:          flows.Password.oauthFlow(),
+			ClientCredentials: flows.ClientCredentials.oauthFlow(),
+			AuthorizationCode: flows.AuthorizationCode.oauthFlow(),
+		},
+	}
+}
+
+// OpenIDConnectSecurityScheme returns the OpenID Connect security scheme, with
+// the url of the OpenID Connect discovery document.
+func OpenIDConnectSecurityScheme(url string) *openapi3.SecurityScheme {
+	return openapi3.NewOIDCSecurityScheme(url)
+}
+
+func (f *OAuthFlow) oauthFlow() *openapi3.OAuthFlow {
+	if f == nil {
+		return nil
+	}
+	scopes := openapi3.StringMap[string]{}
+	for scope, description := range f.Scopes {
+		scopes[scope] = description
+	}
+	return &openapi3.OAuthFlow{
+		AuthorizationURL: f.AuthorizationURL,
+		TokenURL:         f.TokenURL,
+		RefreshURL:       f.RefreshURL,
+		Scopes:           scopes,
+	}
+}
+
+// NoSecurity returns the security requirements of the public routes, which
+// override the default security requirements of the router.
+func NoSecurity() SecurityRequirements {
+	return SecurityRequirements{}
+}
+
+func (s SecurityRequirements) openapi() openapi3.SecurityRequirements {
+	securityRequirements := make(openapi3.SecurityRequirements, 0, len(s))
+	for _, securityRequirement := range s {
+		securityRequirements = append(securityRequirements, openapi3.SecurityRequirement(securityRequirement))
+	}
+	return securityRequirements
+}
+
+// AddSecurityScheme adds the security scheme to the components security schemes.
+// The security requirements of the routes can only use the added schemes.
+func (r Router[_, _]) AddSecurityScheme(name string, scheme *openapi3.SecurityScheme) error {
+	if err := validateComponentName(name); err != nil {
+		return err
+	}
+	if scheme == nil {
+		return fmt.Errorf("security scheme %s is required", name)
+	}
+	components := r.components()
+	if components.SecuritySchemes[name] != nil {
+		return fmt.Errorf("security scheme %s is already defined", name)
+	}
+	if err := scheme.Validate(r.context); err != nil {
+		return fmt.Errorf("security scheme %s: %w", name, err)
+	}
+	if components.SecuritySchemes == nil {
+		components.SecuritySchemes = openapi3.SecuritySchemes{}
+	}
+	components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+	return nil
+}
+
+// checkSecurityRequirements checks that the security requirements use only the
+// defined security schemes and, for OAuth2, the scopes defined by their flows.
+func (r Router[_, _]) checkSecurityRequirements(securityRequirements openapi3.SecurityRequirements) error {
+	var securitySchemes openapi3.SecuritySchemes
+	if r.swaggerSchema.Components != nil {
+		securitySchemes = r.swaggerSchema.Components.SecuritySchemes
+	}
+
+	for _, securityRequirement := range securityRequirements {
+		names := make([]string, 0, len(securityRequirement))
+		for name := range securityRequirement {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			schemeRef := securitySchemes[name]
+			if schemeRef == nil || schemeRef.Value == nil {
+				return fmt.Errorf("unknown security scheme %s", name)
+			}
+			if err := r.checkSecurityScopes(name, schemeRef.Value, securityRequirement[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r Router[_, _]) checkSecurityScopes(name string, scheme *openapi3.SecurityScheme, scopes []string) error {
+	switch scheme.Type {
+	case oauth2SecuritySchemeType:
+		for _, scope := range scopes {
+			if !hasOAuthScope(scheme.Flows, scope) {
+				return fmt.Errorf("scope %s is not defined by the security scheme %s", scope, name)
+			}
+		}
+	case openIDConnectSecuritySchemeType:
+		// The scopes are defined by the OpenID Connect discovery document.
+	default:
+		// In openapi 3.1 the other schemes can require roles, which are not defined in the scheme.
+		if len(scopes) > 0 && !r.openapi31 {
+			return fmt.Errorf("security scheme %s of type %s does not support scopes", name, scheme.Type)
+		}
+	}
+	return nil
+}
+
+func hasOAuthScope(flows *openapi3.OAuthFlows, scope string) bool {
+	if flows == nil {
+		return false
+	}
+	for _, flow := range []*openapi3.OAuthFlow{flows.Implicit, flows.Password, flows.ClientCredentials, flows.AuthorizationCode} {
+		if flow == nil {
+			continue
+		}
+		if _, ok := flow.Scopes[scope]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// operationSecurity returns the security requirements of the operation, or the
+// default ones if the operation does not override them.
+func (r Router[_, _]) operationSecurity(operation Operation) openapi3.SecurityRequirements {
+	if operation.Security != nil {
+		return *operation.Security
+	}
+	return r.swaggerSchema.Security
+}
+
+// withSecurity returns the handler which enforces the security requirements of
+// the operation, with the SecurityAuthenticators option.
+func (r Router[HandlerFunc, _]) withSecurity(handler HandlerFunc, operation Operation) (HandlerFunc, error) {
+	securityRouter, ok := r.router.(apirouter.SecurityRouter[HandlerFunc])
+	if !ok {
+		return handler, fmt.Errorf("the router does not support the security enforcement")
+	}
+
+	policy := security.Policy{
+		Schemes:        map[string]security.Scheme{},
+		Authenticators: *r.securityAuthenticators,
+	}
+	for _, securityRequirement := range r.operationSecurity(operation) {
+		policy.Requirements = append(policy.Requirements, security.Requirement(securityRequirement))
+		for name := range securityRequirement {
+			scheme := r.swaggerSchema.Components.SecuritySchemes[name].Value
+			policy.Schemes[name] = security.Scheme{
+				Type:   scheme.Type,
+				Scheme: scheme.Scheme,
+				In:     scheme.In,
+				Name:   scheme.Name,
+			}
+		}
+	}
+	return securityRouter.WithSecurity(handler, policy), nil
+}
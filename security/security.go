@@ -0,0 +1,252 @@
+// Package security enforces at runtime the security requirements of the routes,
+// authenticating the credentials of the requests with pluggable authenticators.
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/davidebianchi/gswagger/problem"
+)
+
+var (
+	// ErrUnauthorized is returned if the request has no valid credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned if the credentials of the request are valid, but
+	// do not grant the access. Authenticators return it (or an error wrapping
+	// it) to reply with 403 instead of 401.
+	ErrForbidden = errors.New("forbidden")
+)
+
+const (
+	httpSchemeType          = "http"
+	apiKeySchemeType        = "apiKey"
+	oauth2SchemeType        = "oauth2"
+	openIDConnectSchemeType = "openIdConnect"
+)
+
+// Request gives access to the credentials of the request.
+type Request interface {
+	Context() context.Context
+	Header(name string) string
+	Query(name string) string
+	Cookie(name string) string
+}
+
+// Authenticators validate the credentials of the requests, by security scheme
+// type. They receive the name of the security scheme. The security schemes
+// whose authenticator is not set are never satisfied.
+type Authenticators struct {
+	// Bearer validates the token of the http bearer schemes.
+	Bearer func(ctx context.Context, scheme string, token string) error
+	// Basic validates the credentials of the http basic schemes.
+	Basic func(ctx context.Context, scheme string, username string, password string) error
+	// APIKey validates the key of the apiKey schemes.
+	APIKey func(ctx context.Context, scheme string, key string) error
+	// OAuth2 validates the bearer token of the oauth2 and openIdConnect schemes,
+	// which must grant the required scopes.
+	OAuth2 func(ctx context.Context, scheme string, token string, scopes []string) error
+}
+
+// Scheme is the security scheme used to read the credentials of the requests.
+type Scheme struct {
+	// Type is http, apiKey, oauth2 or openIdConnect.
+	Type string
+	// Scheme is bearer or basic, for the http type.
+	Scheme string
+	// In is header, query or cookie, and Name is the name of the key, for the apiKey type.
+	In   string
+	Name string
+}
+
+// Requirement maps the name of each required security scheme to the required scopes.
+type Requirement map[string][]string
+
+// Policy is the security policy of a route.
+type Policy struct {
+	// Requirements of the route: the request must satisfy at least one of them.
+	Requirements []Requirement
+	// Schemes contains the security schemes by name.
+	Schemes        map[string]Scheme
+	Authenticators Authenticators
+}
+
+// Check returns nil if the request satisfies at least one of the requirements,
+// or if there are no requirements. Otherwise, it returns ErrForbidden if the
+// request has been authenticated by a requirement, and ErrUnauthorized if not.
+func (p Policy) Check(req Request) error {
+	if len(p.Requirements) == 0 {
+		return nil
+	}
+	forbidden := false
+	for _, requirement := range p.Requirements {
+		err := p.checkRequirement(req, requirement)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrForbidden) {
+			forbidden = true
+		}
+	}
+	if forbidden {
+		return ErrForbidden
+	}
+	return ErrUnauthorized
+}
+
+func (p Policy) checkRequirement(req Request, requirement Requirement) error {
+	for _, name := range sortedSchemes(requirement) {
+		scheme, ok := p.Schemes[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown security scheme %s", ErrUnauthorized, name)
+		}
+		if err := p.authenticate(req, name, scheme, requirement[name]); err != nil {
+			if errors.Is(err, ErrForbidden) || errors.Is(err, ErrUnauthorized) {
+				return err
+			}
+			return fmt.Errorf("%w: %s", ErrUnauthorized, err)
+		}
+	}
+	return nil
+}
+
+func (p Policy) authenticate(req Request, name string, scheme Scheme, scopes []string) error {
+	ctx := req.Context()
+	switch {
+	case scheme.Type == httpSchemeType && strings.EqualFold(scheme.Scheme, "bearer"):
+		token, ok := bearerToken(req)
+		if !ok || p.Authenticators.Bearer == nil {
+			return ErrUnauthorized
+		}
+		return p.Authenticators.Bearer(ctx, name, token)
+	case scheme.Type == httpSchemeType && strings.EqualFold(scheme.Scheme, "basic"):
+		username, password, ok := basicCredentials(req)
+		if !ok || p.Authenticators.Basic == nil {
+			return ErrUnauthorized
+		}
+		return p.Authenticators.Basic(ctx, name, username, password)
+	case scheme.Type == apiKeySchemeType:
+		key := apiKey(req, scheme)
+		if key == "" || p.Authenticators.APIKey == nil {
+			return ErrUnauthorized
+		}
+		return p.Authenticators.APIKey(ctx, name, key)
+	case scheme.Type == oauth2SchemeType || scheme.Type == openIDConnectSchemeType:
+		token, ok := bearerToken(req)
+		if !ok || p.Authenticators.OAuth2 == nil {
+			return ErrUnauthorized
+		}
+		return p.Authenticators.OAuth2(ctx, name, token, scopes)
+	default:
+		return fmt.Errorf("unsupported security scheme %s", name)
+	}
+}
+
+// Challenge returns the value of the WWW-Authenticate header of the
+// unauthorized responses, with the http authentication schemes of the requirements.
+func (p Policy) Challenge() string {
+	challenges := []string{}
+	found := map[string]bool{}
+	for _, requirement := range p.Requirements {
+		for _, name := range sortedSchemes(requirement) {
+			var challenge string
+			switch scheme := p.Schemes[name]; {
+			case scheme.Type == httpSchemeType && strings.EqualFold(scheme.Scheme, "basic"):
+				challenge = "Basic"
+			case scheme.Type == httpSchemeType && strings.EqualFold(scheme.Scheme, "bearer"),
+				scheme.Type == oauth2SchemeType, scheme.Type == openIDConnectSchemeType:
+				challenge = "Bearer"
+			}
+			if challenge != "" && !found[challenge] {
+				found[challenge] = true
+				challenges = append(challenges, challenge)
+			}
+		}
+	}
+	return strings.Join(challenges, ", ")
+}
+
+// Problem returns the problem details of the error returned by Check: 403 if it
+// is ErrForbidden, 401 otherwise. The detail does not contain the error, which
+// may expose the reason of the failure.
+func Problem(err error) problem.Details {
+	if errors.Is(err, ErrForbidden) {
+		return problem.New(http.StatusForbidden, "the credentials do not grant access to the resource")
+	}
+	return problem.New(http.StatusUnauthorized, "valid credentials are required to access the resource")
+}
+
+// HTTPRequest returns the Request of the net/http request.
+func HTTPRequest(req *http.Request) Request {
+	return httpRequest{req: req}
+}
+
+type httpRequest struct {
+	req *http.Request
+}
+
+func (r httpRequest) Context() context.Context {
+	return r.req.Context()
+}
+
+func (r httpRequest) Header(name string) string {
+	return r.req.Header.Get(name)
+}
+
+func (r httpRequest) Query(name string) string {
+	return r.req.URL.Query().Get(name)
+}
+
+func (r httpRequest) Cookie(name string) string {
+	cookie, err := r.req.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func bearerToken(req Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func basicCredentials(req Request) (string, string, bool) {
+	scheme, encoded, ok := strings.Cut(req.Header("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func apiKey(req Request, scheme Scheme) string {
+	switch scheme.In {
+	case "header":
+		return req.Header(scheme.Name)
+	case "query":
+		return req.Query(scheme.Name)
+	case "cookie":
+		return req.Cookie(scheme.Name)
+	}
+	return ""
+}
+
+func sortedSchemes(requirement Requirement) []string {
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,225 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/problem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	schemes := map[string]Scheme{
+		"bearerAuth":   {Type: "http", Scheme: "bearer"},
+		"basicAuth":    {Type: "http", Scheme: "basic"},
+		"apiKeyHeader": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		"apiKeyQuery":  {Type: "apiKey", In: "query", Name: "api_key"},
+		"apiKeyCookie": {Type: "apiKey", In: "cookie", Name: "session"},
+		"oauth":        {Type: "oauth2"},
+	}
+	authenticators := Authenticators{
+		Bearer: func(ctx context.Context, scheme string, token string) error {
+			if token != "valid-token" {
+				return errors.New("invalid token")
+			}
+			return nil
+		},
+		Basic: func(ctx context.Context, scheme string, username string, password string) error {
+			if username != "user" || password != "secret" {
+				return errors.New("invalid credentials")
+			}
+			return nil
+		},
+		APIKey: func(ctx context.Context, scheme string, key string) error {
+			if key != "valid-key" {
+				return errors.New("invalid key")
+			}
+			return nil
+		},
+		OAuth2: func(ctx context.Context, scheme string, token string, scopes []string) error {
+			if token != "valid-token" {
+				return errors.New("invalid token")
+			}
+			for _, scope := range scopes {
+				if scope != "users:read" {
+					return fmt.Errorf("%w: scope %s not granted", ErrForbidden, scope)
+				}
+			}
+			return nil
+		},
+	}
+
+	tests := []struct {
+		name          string
+		requirements  []Requirement
+		authenticator *Authenticators
+		request       func(req *http.Request)
+		expectedError error
+	}{
+		{
+			name: "no requirements",
+		},
+		{
+			name:          "missing bearer token",
+			requirements:  []Requirement{{"bearerAuth": {}}},
+			expectedError: ErrUnauthorized,
+		},
+		{
+			name:         "valid bearer token",
+			requirements: []Requirement{{"bearerAuth": {}}},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer valid-token")
+			},
+		},
+		{
+			name:         "invalid bearer token",
+			requirements: []Requirement{{"bearerAuth": {}}},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer other-token")
+			},
+			expectedError: ErrUnauthorized,
+		},
+		{
+			name:         "valid basic credentials",
+			requirements: []Requirement{{"basicAuth": {}}},
+			request: func(req *http.Request) {
+				req.SetBasicAuth("user", "secret")
+			},
+		},
+		{
+			name:         "invalid basic credentials",
+			requirements: []Requirement{{"basicAuth": {}}},
+			request: func(req *http.Request) {
+				req.SetBasicAuth("user", "wrong")
+			},
+			expectedError: ErrUnauthorized,
+		},
+		{
+			name:         "api key in header",
+			requirements: []Requirement{{"apiKeyHeader": {}}},
+			request: func(req *http.Request) {
+				req.Header.Set("X-API-Key", "valid-key")
+			},
+		},
+		{
+			name:         "api key in query",
+			requirements: []Requirement{{"apiKeyQuery": {}}},
+			request: func(req *http.Request) {
+				req.URL.RawQuery = "api_key=valid-key"
+			},
+		},
+		{
+			name:         "api key in cookie",
+			requirements: []Requirement{{"apiKeyCookie": {}}},
+			request: func(req *http.Request) {
+				req.AddCookie(&http.Cookie{Name: "session", Value: "valid-key"})
+			},
+		},
+		{
+			name:         "oauth2 scopes granted",
+			requirements: []Requirement{{"oauth": {"users:read"}}},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer valid-token")
+			},
+		},
+		{
+			name:         "oauth2 scopes not granted",
+			requirements: []Requirement{{"oauth": {"users:write"}}},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer valid-token")
+			},
+			expectedError: ErrForbidden,
+		},
+		{
+			name:         "all the schemes of a requirement are required",
+			requirements: []Requirement{{"bearerAuth": {}, "apiKeyHeader": {}}},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer valid-token")
+			},
+			expectedError: ErrUnauthorized,
+		},
+		{
+			name:         "one of the requirements is enough",
+			requirements: []Requirement{{"oauth": {"users:write"}}, {"apiKeyHeader": {}}},
+			request: func(req *http.Request) {
+				req.Header.Set("X-API-Key", "valid-key")
+			},
+		},
+		{
+			name:          "authenticator not set",
+			requirements:  []Requirement{{"bearerAuth": {}}},
+			authenticator: &Authenticators{},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer valid-token")
+			},
+			expectedError: ErrUnauthorized,
+		},
+		{
+			name:         "unknown scheme",
+			requirements: []Requirement{{"jwt": {}}},
+			request: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer valid-token")
+			},
+			expectedError: ErrUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.request != nil {
+				test.request(req)
+			}
+			policy := Policy{
+				Requirements:   test.requirements,
+				Schemes:        schemes,
+				Authenticators: authenticators,
+			}
+			if test.authenticator != nil {
+				policy.Authenticators = *test.authenticator
+			}
+
+			err := policy.Check(HTTPRequest(req))
+			if test.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestPolicyChallenge(t *testing.T) {
+	policy := Policy{
+		Requirements: []Requirement{
+			{"oauth": {"users:read"}},
+			{"basicAuth": {}, "bearerAuth": {}},
+			{"apiKey": {}},
+		},
+		Schemes: map[string]Scheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer"},
+			"basicAuth":  {Type: "http", Scheme: "basic"},
+			"apiKey":     {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			"oauth":      {Type: "oauth2"},
+		},
+	}
+	require.Equal(t, "Bearer, Basic", policy.Challenge())
+	require.Equal(t, "", Policy{}.Challenge())
+}
+
+func TestProblem(t *testing.T) {
+	require.Equal(t, problem.Details{
+		Title:  "Unauthorized",
+		Status: http.StatusUnauthorized,
+		Detail: "valid credentials are required to access the resource",
+	}, Problem(ErrUnauthorized))
+	require.Equal(t, problem.Details{
+		Title:  "Forbidden",
+		Status: http.StatusForbidden,
+		Detail: "the credentials do not grant access to the resource",
+	}, Problem(fmt.Errorf("%w: scope not granted", ErrForbidden)))
+}
@@ -0,0 +1,228 @@
+package swagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/security"
+	"github.com/davidebianchi/gswagger/support/gorilla"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurity(t *testing.T) {
+	newRouter := func(t *testing.T, r *mux.Router, options Options) *TestRouter {
+		t.Helper()
+		router := newTestRouter(t, r, options)
+
+		require.NoError(t, router.AddSecurityScheme("bearerAuth", BearerSecurityScheme("JWT")))
+		require.NoError(t, router.AddSecurityScheme("basicAuth", BasicSecurityScheme()))
+		require.NoError(t, router.AddSecurityScheme("apiKey", APIKeySecurityScheme("query", "api_key")))
+		require.NoError(t, router.AddSecurityScheme("oauth", OAuth2SecurityScheme(OAuthFlows{
+			AuthorizationCode: &OAuthFlow{
+				AuthorizationURL: "https://example.com/oauth/authorize",
+				TokenURL:         "https://example.com/oauth/token",
+				Scopes: map[string]string{
+					"users:read": "read the users",
+				},
+			},
+			ClientCredentials: &OAuthFlow{
+				TokenURL: "https://example.com/oauth/token",
+				Scopes: map[string]string{
+					"users:write": "write the users",
+				},
+			},
+		})))
+		require.NoError(t, router.AddSecurityScheme("oidc", OpenIDConnectSecurityScheme("https://example.com/.well-known/openid-configuration")))
+		return router
+	}
+
+	t.Run("default security and route overrides", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newRouter(t, r, Options{
+			Security: SecurityRequirements{{"bearerAuth": {}}},
+		})
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			Security: SecurityRequirements{
+				{"oauth": {"users:read", "users:write"}},
+				{"apiKey": {}, "basicAuth": {}},
+				{"oidc": {"openid"}},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodGet, "/health", okHandler, Definitions{
+			Security: NoSecurity(),
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/security-schemes.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("invalid security requirements", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter(), Options{})
+
+		tests := []struct {
+			name          string
+			security      SecurityRequirements
+			expectedError string
+		}{
+			{
+				name:          "unknown security scheme",
+				security:      SecurityRequirements{{"bearerAuth": {}, "jwt": {}}},
+				expectedError: "unknown security scheme jwt",
+			},
+			{
+				name:          "unknown oauth2 scope",
+				security:      SecurityRequirements{{"oauth": {"users:read", "users:delete"}}},
+				expectedError: "scope users:delete is not defined by the security scheme oauth",
+			},
+			{
+				name:          "scopes not supported",
+				security:      SecurityRequirements{{"bearerAuth": {"admin"}}},
+				expectedError: "security scheme bearerAuth of type http does not support scopes",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+					Security: test.security,
+				})
+				require.EqualError(t, err, fmt.Sprintf("%s: GET /users: security: %s", ErrSecurity, test.expectedError))
+			})
+		}
+	})
+
+	t.Run("unknown default security scheme", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter(), Options{
+			Security: SecurityRequirements{{"jwt": {}}},
+		})
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /users: security: unknown security scheme jwt", ErrSecurity))
+
+		_, err = router.AddRoute(http.MethodGet, "/health", okHandler, Definitions{
+			Security: NoSecurity(),
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.EqualError(t, err, fmt.Sprintf("%s: default security: unknown security scheme jwt", ErrValidatingOAS))
+	})
+
+	t.Run("roles are supported in openapi 3.1", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter(), Options{Openapi31: true})
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{
+			Security: SecurityRequirements{{"bearerAuth": {"admin"}}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid security schemes", func(t *testing.T) {
+		router := newRouter(t, mux.NewRouter(), Options{})
+
+		err := router.AddSecurityScheme("bearerAuth", BearerSecurityScheme(""))
+		require.EqualError(t, err, "security scheme bearerAuth is already defined")
+
+		err = router.AddSecurityScheme("apiKeyHeader", APIKeySecurityScheme("header", ""))
+		require.ErrorContains(t, err, "security scheme apiKeyHeader: ")
+
+		err = router.AddSecurityScheme("empty", nil)
+		require.EqualError(t, err, "security scheme empty is required")
+	})
+
+	t.Run("enforce security requirements", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newRouter(t, r, Options{
+			Security: SecurityRequirements{{"bearerAuth": {}}},
+			SecurityAuthenticators: &security.Authenticators{
+				Bearer: func(ctx context.Context, scheme string, token string) error {
+					if token != "valid-token" {
+						return errors.New("invalid token")
+					}
+					return nil
+				},
+				OAuth2: func(ctx context.Context, scheme string, token string, scopes []string) error {
+					return fmt.Errorf("%w: scopes not granted", security.ErrForbidden)
+				},
+			},
+		})
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			Security: SecurityRequirements{{"oauth": {"users:write"}}},
+		})
+		require.NoError(t, err)
+		_, err = router.AddRoute(http.MethodGet, "/health", okHandler, Definitions{
+			Security: NoSecurity(),
+		})
+		require.NoError(t, err)
+
+		tests := []struct {
+			name           string
+			method         string
+			path           string
+			authorization  string
+			expectedStatus int
+		}{
+			{name: "default security without credentials", method: http.MethodGet, path: "/users", expectedStatus: http.StatusUnauthorized},
+			{name: "default security with credentials", method: http.MethodGet, path: "/users", authorization: "Bearer valid-token", expectedStatus: http.StatusOK},
+			{name: "scopes not granted", method: http.MethodPost, path: "/users", authorization: "Bearer valid-token", expectedStatus: http.StatusForbidden},
+			{name: "public route", method: http.MethodGet, path: "/health", expectedStatus: http.StatusOK},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(test.method, test.path, nil)
+				if test.authorization != "" {
+					req.Header.Set("Authorization", test.authorization)
+				}
+				r.ServeHTTP(w, req)
+				require.Equal(t, test.expectedStatus, w.Result().StatusCode)
+				if test.expectedStatus != http.StatusOK {
+					require.Equal(t, "application/problem+json", w.Result().Header.Get("Content-Type"))
+				}
+			})
+		}
+	})
+
+	t.Run("router without security enforcement", func(t *testing.T) {
+		router, err := NewRouter(apirouterWithoutSecurity{gorilla.NewRouter(mux.NewRouter())}, Options{
+			Openapi:                getBaseSwagger(t),
+			SecurityAuthenticators: &security.Authenticators{},
+		})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{})
+		require.EqualError(t, err, fmt.Sprintf("%s: GET /users: security: the router does not support the security enforcement", ErrSecurity))
+	})
+}
+
+// apirouterWithoutSecurity hides the security enforcement of the router.
+type apirouterWithoutSecurity struct {
+	apirouter.Router[gorilla.HandlerFunc, gorilla.Route]
+}
@@ -2,7 +2,10 @@ package echo
 
 import (
 	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/problem"
+	"github.com/davidebianchi/gswagger/security"
 
+	"encoding/json"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -29,8 +32,33 @@ func (r echoRouter) TransformPathToOasPath(path string) string {
 	return apirouter.TransformPathParamsWithColon(path)
 }
 
+// WithSecurity returns the handler which calls the given one only if the
+// request satisfies the security policy.
+func (r echoRouter) WithSecurity(handler echo.HandlerFunc, policy security.Policy) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := policy.Check(security.HTTPRequest(c.Request())); err != nil {
+			details := security.Problem(err)
+			if challenge := policy.Challenge(); challenge != "" && details.Status == http.StatusUnauthorized {
+				c.Response().Header().Set("WWW-Authenticate", challenge)
+			}
+			return WriteProblem(c, details)
+		}
+		return handler(c)
+	}
+}
+
 func NewRouter(router *echo.Echo) apirouter.Router[echo.HandlerFunc, Route] {
 	return echoRouter{
 		router: router,
 	}
 }
+
+// WriteProblem writes the problem details in the response, with its status code
+// and the application/problem+json content type.
+func WriteProblem(c echo.Context, details problem.Details) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	return c.Blob(problem.StatusCode(details), problem.ContentType, data)
+}
@@ -1,7 +1,13 @@
 package fiber
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+
 	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/problem"
+	"github.com/davidebianchi/gswagger/security"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -29,6 +35,53 @@ func (r fiberRouter) SwaggerHandler(contentType string, blob []byte) HandlerFunc
 	}
 }
 
+// WithSecurity returns the handler which calls the given one only if the
+// request satisfies the security policy.
+func (r fiberRouter) WithSecurity(handler HandlerFunc, policy security.Policy) HandlerFunc {
+	return func(c *fiber.Ctx) error {
+		if err := policy.Check(request{ctx: c}); err != nil {
+			details := security.Problem(err)
+			if challenge := policy.Challenge(); challenge != "" && details.Status == http.StatusUnauthorized {
+				c.Set("WWW-Authenticate", challenge)
+			}
+			return WriteProblem(c, details)
+		}
+		return handler(c)
+	}
+}
+
 func (r fiberRouter) TransformPathToOasPath(path string) string {
 	return apirouter.TransformPathParamsWithColon(path)
 }
+
+// WriteProblem writes the problem details in the response, with its status code
+// and the application/problem+json content type.
+func WriteProblem(c *fiber.Ctx, details problem.Details) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	c.Set("Content-Type", problem.ContentType)
+	return c.Status(problem.StatusCode(details)).Send(data)
+}
+
+// request is the security.Request of the fiber context.
+type request struct {
+	ctx *fiber.Ctx
+}
+
+func (r request) Context() context.Context {
+	return r.ctx.UserContext()
+}
+
+func (r request) Header(name string) string {
+	return r.ctx.Get(name)
+}
+
+func (r request) Query(name string) string {
+	return r.ctx.Query(name)
+}
+
+func (r request) Cookie(name string) string {
+	return r.ctx.Cookies(name)
+}
@@ -1,12 +1,16 @@
 package fiber
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/problem"
+	"github.com/davidebianchi/gswagger/security"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
@@ -60,4 +64,64 @@ func TestFiberRouterSupport(t *testing.T) {
 			require.Equal(t, "some data", string(body))
 		})
 	})
+
+	t.Run("write problem details", func(t *testing.T) {
+		fiberRouter.Get("/problem", func(c *fiber.Ctx) error {
+			details := problem.New(http.StatusNotFound, "user not found")
+			details.Extensions = map[string]any{"userId": "123"}
+			return WriteProblem(c, details)
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/problem", nil)
+
+		resp, err := fiberRouter.Test(r)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title":"Not Found","status":404,"detail":"user not found","userId":"123"}`, string(body))
+	})
+
+	t.Run("enforce security policy", func(t *testing.T) {
+		policy := security.Policy{
+			Requirements: []security.Requirement{{"bearerAuth": {}}},
+			Schemes: map[string]security.Scheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+			Authenticators: security.Authenticators{
+				Bearer: func(ctx context.Context, scheme string, token string) error {
+					if token != "valid-token" {
+						return errors.New("invalid token")
+					}
+					return nil
+				},
+			},
+		}
+		secured, ok := ar.(apirouter.SecurityRouter[HandlerFunc])
+		require.True(t, ok)
+		ar.AddRoute(http.MethodGet, "/secured", secured.WithSecurity(func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusOK)
+		}, policy))
+
+		t.Run("rejects the request without credentials", func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/secured", nil)
+
+			resp, err := fiberRouter.Test(r)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+			require.Equal(t, "Bearer", resp.Header.Get("WWW-Authenticate"))
+		})
+
+		t.Run("accepts the request with valid credentials", func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/secured", nil)
+			r.Header.Set("Authorization", "Bearer valid-token")
+
+			resp, err := fiberRouter.Test(r)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	})
 }
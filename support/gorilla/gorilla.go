@@ -2,6 +2,8 @@ package gorilla
 
 import (
 	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/problem"
+	"github.com/davidebianchi/gswagger/security"
 
 	"net/http"
 
@@ -32,8 +34,30 @@ func (r gorillaRouter) TransformPathToOasPath(path string) string {
 	return path
 }
 
+// WithSecurity returns the handler which calls the given one only if the
+// request satisfies the security policy.
+func (r gorillaRouter) WithSecurity(handler HandlerFunc, policy security.Policy) HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if err := policy.Check(security.HTTPRequest(req)); err != nil {
+			details := security.Problem(err)
+			if challenge := policy.Challenge(); challenge != "" && details.Status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", challenge)
+			}
+			WriteProblem(w, details)
+			return
+		}
+		handler(w, req)
+	}
+}
+
 func NewRouter(router *mux.Router) apirouter.Router[HandlerFunc, Route] {
 	return gorillaRouter{
 		router: router,
 	}
 }
+
+// WriteProblem writes the problem details in the response, with its status code
+// and the application/problem+json content type.
+func WriteProblem(w http.ResponseWriter, details problem.Details) error {
+	return problem.Write(w, details)
+}
@@ -1,12 +1,16 @@
 package gorilla
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/davidebianchi/gswagger/apirouter"
+	"github.com/davidebianchi/gswagger/problem"
+	"github.com/davidebianchi/gswagger/security"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 )
@@ -63,4 +67,67 @@ func TestGorillaMuxRouter(t *testing.T) {
 			require.Equal(t, "some data", string(body))
 		})
 	})
+
+	t.Run("write problem details", func(t *testing.T) {
+		muxRouter.HandleFunc("/problem", func(w http.ResponseWriter, req *http.Request) {
+			details := problem.New(http.StatusNotFound, "user not found")
+			details.Extensions = map[string]any{"userId": "123"}
+			require.NoError(t, WriteProblem(w, details))
+		}).Methods(http.MethodGet)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/problem", nil)
+
+		muxRouter.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+		require.Equal(t, "application/problem+json", w.Result().Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(w.Result().Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title":"Not Found","status":404,"detail":"user not found","userId":"123"}`, string(body))
+	})
+
+	t.Run("enforce security policy", func(t *testing.T) {
+		policy := security.Policy{
+			Requirements: []security.Requirement{{"bearerAuth": {}}},
+			Schemes: map[string]security.Scheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+			Authenticators: security.Authenticators{
+				Bearer: func(ctx context.Context, scheme string, token string) error {
+					if token != "valid-token" {
+						return errors.New("invalid token")
+					}
+					return nil
+				},
+			},
+		}
+		secured, ok := ar.(apirouter.SecurityRouter[HandlerFunc])
+		require.True(t, ok)
+		ar.AddRoute(http.MethodGet, "/secured", secured.WithSecurity(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}, policy))
+
+		t.Run("rejects the request without credentials", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/secured", nil)
+
+			muxRouter.ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+			require.Equal(t, "application/problem+json", w.Result().Header.Get("Content-Type"))
+			require.Equal(t, "Bearer", w.Result().Header.Get("WWW-Authenticate"))
+		})
+
+		t.Run("accepts the request with valid credentials", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/secured", nil)
+			r.Header.Set("Authorization", "Bearer valid-token")
+
+			muxRouter.ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+		})
+	})
 }
@@ -0,0 +1,152 @@
+package swagger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	tagDisplayNameExtension = "x-displayName"
+	tagGroupsExtension      = "x-tagGroups"
+)
+
+// Tag contains the metadata of a tag used by the routes.
+type Tag struct {
+	Name         string
+	Description  string
+	ExternalDocs *ExternalDocs
+	// DisplayName is the name of the tag shown by the documentation tools,
+	// set as the x-displayName extension.
+	DisplayName string
+	// Group is the name of the group of the tag, in the x-tagGroups extension.
+	// Tools supporting the extension hide the tags without a group.
+	Group string
+}
+
+// ExternalDocs is the external documentation of a tag.
+type ExternalDocs struct {
+	Description string
+	URL         string
+}
+
+// UndeclaredTagHandler is called by GenerateAndExposeOpenapi with each tag used
+// by the routes and not declared with AddTag. If it returns an error,
+// GenerateAndExposeOpenapi fails with that error.
+type UndeclaredTagHandler func(tag string) error
+
+// RejectUndeclaredTags is the UndeclaredTagHandler which fails for each undeclared tag.
+func RejectUndeclaredTags(tag string) error {
+	return fmt.Errorf("tag %s is used but not declared", tag)
+}
+
+// tagGroup is an item of the x-tagGroups extension.
+type tagGroup struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+// AddTag declares the tag in the openapi tags, which are listed in the order
+// they are declared.
+func (r Router[_, _]) AddTag(tag Tag) error {
+	if tag.Name == "" {
+		return fmt.Errorf("tag name is required")
+	}
+	if r.swaggerSchema.Tags.Get(tag.Name) != nil {
+		return fmt.Errorf("tag %s is already declared", tag.Name)
+	}
+
+	oasTag := &openapi3.Tag{
+		Name:        tag.Name,
+		Description: tag.Description,
+	}
+	if tag.ExternalDocs != nil {
+		oasTag.ExternalDocs = &openapi3.ExternalDocs{
+			Description: tag.ExternalDocs.Description,
+			URL:         tag.ExternalDocs.URL,
+		}
+		if err := oasTag.ExternalDocs.Validate(r.context); err != nil {
+			return fmt.Errorf("tag %s: %w", tag.Name, err)
+		}
+	}
+	if tag.DisplayName != "" {
+		oasTag.Extensions = map[string]any{tagDisplayNameExtension: tag.DisplayName}
+	}
+	if tag.Group != "" {
+		r.tagGroups[tag.Name] = tag.Group
+	}
+	r.swaggerSchema.Tags = append(r.swaggerSchema.Tags, oasTag)
+	return nil
+}
+
+// setTagGroups sets the x-tagGroups extension with the groups of the declared
+// tags, in the order of their first tag.
+func (r Router[_, _]) setTagGroups() {
+	if len(r.tagGroups) == 0 {
+		return
+	}
+	groups := []*tagGroup{}
+	groupsByName := map[string]*tagGroup{}
+	for _, tag := range r.swaggerSchema.Tags {
+		name, ok := r.tagGroups[tag.Name]
+		if !ok {
+			continue
+		}
+		group, ok := groupsByName[name]
+		if !ok {
+			group = &tagGroup{Name: name}
+			groupsByName[name] = group
+			groups = append(groups, group)
+		}
+		group.Tags = append(group.Tags, tag.Name)
+	}
+	if r.swaggerSchema.Extensions == nil {
+		r.swaggerSchema.Extensions = map[string]any{}
+	}
+	r.swaggerSchema.Extensions[tagGroupsExtension] = groups
+}
+
+// checkUndeclaredTags calls the OnUndeclaredTag handler, if set, with the tags
+// used by the routes and the webhooks, and not declared.
+func (r Router[_, _]) checkUndeclaredTags() error {
+	if r.onUndeclaredTag == nil {
+		return nil
+	}
+	for _, tag := range r.undeclaredTags() {
+		if err := r.onUndeclaredTag(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undeclaredTags returns, sorted, the tags used by the routes and the webhooks,
+// and not declared.
+func (r Router[_, _]) undeclaredTags() []string {
+	pathItems := []*openapi3.PathItem{}
+	for _, pathItem := range r.swaggerSchema.Paths.Map() {
+		pathItems = append(pathItems, pathItem)
+	}
+	for _, pathItem := range r.webhooks {
+		pathItems = append(pathItems, pathItem)
+	}
+
+	undeclared := map[string]bool{}
+	for _, pathItem := range pathItems {
+		for _, operation := range pathItem.Operations() {
+			for _, tag := range operation.Tags {
+				if r.swaggerSchema.Tags.Get(tag) == nil {
+					undeclared[tag] = true
+				}
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(undeclared))
+	for tag := range undeclared {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
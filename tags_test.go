@@ -0,0 +1,134 @@
+package swagger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/davidebianchi/gswagger/support/gorilla"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	t.Run("tags metadata, order and groups", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{})
+
+		require.NoError(t, router.AddTag(Tag{
+			Name:        "users",
+			Description: "Users management",
+			ExternalDocs: &ExternalDocs{
+				Description: "Users guide",
+				URL:         "https://example.com/docs/users",
+			},
+			DisplayName: "Users",
+			Group:       "Accounts",
+		}))
+		require.NoError(t, router.AddTag(Tag{Name: "health", Group: "Monitoring"}))
+		require.NoError(t, router.AddTag(Tag{Name: "groups", DisplayName: "User groups", Group: "Accounts"}))
+
+		_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{Tags: []string{"users"}})
+		require.NoError(t, err)
+
+		subRouter, err := router.SubRouter(gorilla.NewRouter(r.NewRoute().Subrouter()), SubRouterOptions{PathPrefix: "/v1"})
+		require.NoError(t, err)
+		require.NoError(t, subRouter.AddTag(Tag{Name: "admin"}))
+		_, err = subRouter.AddRoute(http.MethodGet, "/groups", okHandler, Definitions{Tags: []string{"groups", "admin"}})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodGet, "/health", okHandler, Definitions{Tags: []string{"health"}})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/tag-metadata.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+		require.NoError(t, router.AddTag(Tag{Name: "users"}))
+
+		tests := []struct {
+			name          string
+			tag           Tag
+			expectedError string
+		}{
+			{
+				name:          "without name",
+				tag:           Tag{Description: "some description"},
+				expectedError: "tag name is required",
+			},
+			{
+				name:          "already declared",
+				tag:           Tag{Name: "users"},
+				expectedError: "tag users is already declared",
+			},
+			{
+				name:          "external docs without url",
+				tag:           Tag{Name: "groups", ExternalDocs: &ExternalDocs{Description: "Groups guide"}},
+				expectedError: "tag groups: url is required",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := router.AddTag(test.tag)
+				require.EqualError(t, err, test.expectedError)
+			})
+		}
+	})
+
+	t.Run("undeclared tags", func(t *testing.T) {
+		addRoutes := func(t *testing.T, router *TestRouter) {
+			t.Helper()
+			require.NoError(t, router.AddTag(Tag{Name: "users"}))
+			_, err := router.AddRoute(http.MethodGet, "/users", okHandler, Definitions{Tags: []string{"users", "legacy"}})
+			require.NoError(t, err)
+			_, err = router.AddRoute(http.MethodGet, "/groups", okHandler, Definitions{Tags: []string{"groups"}})
+			require.NoError(t, err)
+		}
+
+		t.Run("allowed by default", func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{})
+			addRoutes(t, router)
+
+			require.NoError(t, router.GenerateAndExposeOpenapi())
+		})
+
+		t.Run("warning", func(t *testing.T) {
+			undeclared := []string{}
+			router := newTestRouter(t, mux.NewRouter(), Options{
+				OnUndeclaredTag: func(tag string) error {
+					undeclared = append(undeclared, tag)
+					return nil
+				},
+			})
+			addRoutes(t, router)
+
+			require.NoError(t, router.GenerateAndExposeOpenapi())
+			require.Equal(t, []string{"groups", "legacy"}, undeclared)
+		})
+
+		t.Run("rejected", func(t *testing.T) {
+			router := newTestRouter(t, mux.NewRouter(), Options{
+				OnUndeclaredTag: RejectUndeclaredTags,
+			})
+			addRoutes(t, router)
+
+			err := router.GenerateAndExposeOpenapi()
+			require.EqualError(t, err, fmt.Sprintf("%s: tag groups is used but not declared", ErrValidatingOAS))
+		})
+	})
+}
@@ -0,0 +1,346 @@
+package swagger
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+const validateTagName = "validate"
+
+// UnsupportedValidateTagHandler is called with the struct field and the part of
+// its validate tag which can't be translated to a schema constraint.
+// If it returns an error, AddRoute fails with that error.
+type UnsupportedValidateTagHandler func(field reflect.StructField, tag string) error
+
+var oneOfValuesRegexp = regexp.MustCompile(`'[^']*'|\S+`)
+
+var validatePatterns = map[string]string{
+	"alpha":        `^[a-zA-Z]+$`,
+	"alphanum":     `^[a-zA-Z0-9]+$`,
+	"numeric":      `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"number":       `^[0-9]+$`,
+	"hexadecimal":  `^(0[xX])?[0-9a-fA-F]+$`,
+	"lowercase":    `^[^A-Z]*$`,
+	"uppercase":    `^[^a-z]*$`,
+	"e164":         `^\+[1-9]?[0-9]{7,14}$`,
+	"base64":       `^(?:[A-Za-z0-9+\/]{4})*(?:[A-Za-z0-9+\/]{2}==|[A-Za-z0-9+\/]{3}=|[A-Za-z0-9+\/]{4})$`,
+	"alphaunicode": `^[\p{L}]+$`,
+}
+
+var validateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"hostname": "hostname",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"uuid":     "uuid",
+	"uuid3":    "uuid",
+	"uuid4":    "uuid",
+	"uuid5":    "uuid",
+}
+
+// validateTagsTranslator sets the constraints of the validate struct tags in
+// the reflected json schema.
+type validateTagsTranslator struct {
+	definitions   jsonschema.Definitions
+	onUnsupported UnsupportedValidateTagHandler
+	unsupported   []string
+}
+
+func (r Router[_, _]) applyValidateTags(schema *jsonschema.Schema, definitions jsonschema.Definitions, t reflect.Type) error {
+	translator := &validateTagsTranslator{
+		definitions:   definitions,
+		onUnsupported: r.onUnsupportedValidateTag,
+	}
+	err := walkStructFields(schema, t, definitions, func(parent *jsonschema.Schema, name string, property *jsonschema.Schema, field reflect.StructField) error {
+		tag := field.Tag.Get(validateTagName)
+		if tag == "" {
+			return nil
+		}
+		return translator.translate(parent, name, nullableSchema(property), field, field.Type, strings.Split(tag, ","))
+	})
+	if err != nil {
+		return err
+	}
+	if len(translator.unsupported) > 0 {
+		return fmt.Errorf("unsupported validate tags: %s", strings.Join(translator.unsupported, ", "))
+	}
+	return nil
+}
+
+// translate sets on the property schema the constraints of the validate tags.
+func (v *validateTagsTranslator) translate(parent *jsonschema.Schema, name string, property *jsonschema.Schema, field reflect.StructField, t reflect.Type, tags []string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i, tag := range tags {
+		key, param, _ := strings.Cut(tag, "=")
+		var supported bool
+		switch key {
+		case "", "omitempty":
+			supported = true
+		case "required":
+			if parent != nil {
+				parent.Required = appendUnique(parent.Required, name)
+				supported = true
+			}
+		case "dive":
+			if len(tags) == i+1 {
+				// Without element tags, the element schema is not copied.
+				supported = hasElements(t.Kind())
+				break
+			}
+			items := v.elementSchema(property, t)
+			if items == nil {
+				break
+			}
+			return v.translate(nil, "", items, field, t.Elem(), tags[i+1:])
+		case "min", "gte":
+			supported = setLowerBound(property, t, param, false)
+		case "gt":
+			supported = setLowerBound(property, t, param, true)
+		case "max", "lte":
+			supported = setUpperBound(property, t, param, false)
+		case "lt":
+			supported = setUpperBound(property, t, param, true)
+		case "len":
+			supported = setLowerBound(property, t, param, false) && setUpperBound(property, t, param, false)
+		case "oneof":
+			supported = setEnum(property, t, param)
+		case "unique":
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+				property.UniqueItems = true
+				supported = true
+			}
+		case "startswith":
+			supported = setPattern(property, t, "^"+regexp.QuoteMeta(param))
+		case "endswith":
+			supported = setPattern(property, t, regexp.QuoteMeta(param)+"$")
+		case "contains":
+			supported = setPattern(property, t, regexp.QuoteMeta(param))
+		default:
+			if format, ok := validateFormats[key]; ok && param == "" && t.Kind() == reflect.String {
+				property.Format = format
+				supported = true
+			} else if pattern, ok := validatePatterns[key]; ok && param == "" {
+				supported = setPattern(property, t, pattern)
+			}
+		}
+		if !supported {
+			if err := v.reportUnsupported(field, tag); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validateTagsTranslator) reportUnsupported(field reflect.StructField, tag string) error {
+	if v.onUnsupported != nil {
+		return v.onUnsupported(field, tag)
+	}
+	v.unsupported = append(v.unsupported, fmt.Sprintf("%s (field %s)", tag, field.Name))
+	return nil
+}
+
+// elementSchema returns the schema of the elements of the property, which is
+// set in the property as a copy, so their constraints are local to the field.
+// If the property references a named type, the copy is set beside the $ref
+// (then wrapped in an allOf) and the shared definition is never changed.
+func (v *validateTagsTranslator) elementSchema(property *jsonschema.Schema, t reflect.Type) *jsonschema.Schema {
+	definition := resolveDefinition(property, v.definitions)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if definition.Items == nil {
+			return nil
+		}
+		property.Items = localSchema(definition.Items)
+		return property.Items
+	case reflect.Map:
+		if definition.AdditionalProperties == nil {
+			return nil
+		}
+		property.AdditionalProperties = localSchema(definition.AdditionalProperties)
+		return property.AdditionalProperties
+	}
+	return nil
+}
+
+// localSchema returns a copy of the schema, whose keywords can be set without
+// changing the original one.
+func localSchema(schema *jsonschema.Schema) *jsonschema.Schema {
+	local := *schema
+	local.Extras = maps.Clone(schema.Extras)
+	return &local
+}
+
+func setLowerBound(schema *jsonschema.Schema, t reflect.Type, param string, exclusive bool) bool {
+	switch {
+	case isNumberKind(t.Kind()):
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return false
+		}
+		schema.Minimum = json.Number(param)
+		if exclusive {
+			setExtra(schema, "exclusiveMinimum", true)
+		}
+		return true
+	case hasLength(t.Kind()):
+		value, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return false
+		}
+		if exclusive {
+			value++
+		}
+		switch t.Kind() {
+		case reflect.String:
+			schema.MinLength = &value
+		case reflect.Map:
+			schema.MinProperties = &value
+		default:
+			schema.MinItems = &value
+		}
+		return true
+	}
+	return false
+}
+
+func setUpperBound(schema *jsonschema.Schema, t reflect.Type, param string, exclusive bool) bool {
+	switch {
+	case isNumberKind(t.Kind()):
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return false
+		}
+		schema.Maximum = json.Number(param)
+		if exclusive {
+			setExtra(schema, "exclusiveMaximum", true)
+		}
+		return true
+	case hasLength(t.Kind()):
+		value, err := strconv.ParseUint(param, 10, 64)
+		if err != nil || (exclusive && value == 0) {
+			return false
+		}
+		if exclusive {
+			value--
+		}
+		switch t.Kind() {
+		case reflect.String:
+			schema.MaxLength = &value
+		case reflect.Map:
+			schema.MaxProperties = &value
+		default:
+			schema.MaxItems = &value
+		}
+		return true
+	}
+	return false
+}
+
+func setEnum(schema *jsonschema.Schema, t reflect.Type, param string) bool {
+	values := oneOfValuesRegexp.FindAllString(param, -1)
+	if len(values) == 0 {
+		return false
+	}
+	enum := make([]any, 0, len(values))
+	for _, value := range values {
+		switch {
+		case t.Kind() == reflect.String:
+			enum = append(enum, strings.Trim(value, "'"))
+		case isNumberKind(t.Kind()):
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return false
+			}
+			enum = append(enum, json.Number(value))
+		default:
+			return false
+		}
+	}
+	schema.Enum = enum
+	return true
+}
+
+func setPattern(schema *jsonschema.Schema, t reflect.Type, pattern string) bool {
+	if t.Kind() != reflect.String || schema.Pattern != "" {
+		return false
+	}
+	schema.Pattern = pattern
+	return true
+}
+
+func setExtra(schema *jsonschema.Schema, key string, value any) {
+	if schema.Extras == nil {
+		schema.Extras = map[string]any{}
+	}
+	schema.Extras[key] = value
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func hasElements(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+func hasLength(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// jsonFieldName returns the name of the field in the json schema, following
+// the encoding/json rules. If the field is embedded, its properties are
+// part of the parent object.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	jsonTags := strings.Split(field.Tag.Get("json"), ",")
+	if jsonTags[0] == "-" && len(jsonTags) == 1 {
+		return "", false
+	}
+	if field.Anonymous && jsonTags[0] == "" {
+		t := field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true
+		}
+	}
+	if !field.IsExported() {
+		return "", false
+	}
+	if jsonTags[0] != "" {
+		return jsonTags[0], false
+	}
+	return field.Name, false
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
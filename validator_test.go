@@ -0,0 +1,192 @@
+package swagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedAddress struct {
+	Street  string `json:"street,omitempty" validate:"required,min=3"`
+	ZipCode string `json:"zipCode,omitempty" validate:"omitempty,len=5,numeric"`
+}
+
+type validatedUser struct {
+	Name      string             `json:"name,omitempty" validate:"required,min=3,max=64"`
+	Email     string             `json:"email" validate:"required,email"`
+	Role      string             `json:"role,omitempty" validate:"oneof=admin 'power user' guest"`
+	Age       int                `json:"age,omitempty" validate:"gte=18,lt=130"`
+	Score     *float64           `json:"score,omitempty" validate:"omitempty,gt=0,lte=10"`
+	Level     int                `json:"level,omitempty" validate:"oneof=1 2 3"`
+	Website   string             `json:"website,omitempty" validate:"url"`
+	Tags      []string           `json:"tags,omitempty" validate:"min=1,unique,dive,alphanum,max=10"`
+	Labels    map[string]string  `json:"labels,omitempty" validate:"max=5"`
+	Code      string             `json:"code,omitempty" validate:"startswith=US-"`
+	Addresses []validatedAddress `json:"addresses,omitempty" validate:"dive"`
+}
+
+type names []string
+
+type validatedNames struct {
+	Short names `json:"short" validate:"dive,max=5"`
+	Long  names `json:"long" validate:"dive,max=50"`
+}
+
+type validatedAliases struct {
+	Aliases names `json:"aliases" validate:"min=1,dive,min=2"`
+}
+
+func TestValidateTags(t *testing.T) {
+	t.Run("translate validate tags to schema constraints", func(t *testing.T) {
+		tests := []struct {
+			name                string
+			useSchemaComponents bool
+			fixturesPath        string
+		}{
+			{
+				name:         "inlined schemas",
+				fixturesPath: "testdata/validate-tags.json",
+			},
+			{
+				name:                "with schema components",
+				useSchemaComponents: true,
+				fixturesPath:        "testdata/validate-tags-components.json",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				r := mux.NewRouter()
+				router := newTestRouter(t, r, Options{
+					ValidateTags:        true,
+					UseSchemaComponents: test.useSchemaComponents,
+				})
+
+				_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+					RequestBody: &ContentValue{
+						Content: Content{
+							jsonType: {Value: &validatedUser{}},
+						},
+					},
+				})
+				require.NoError(t, err)
+
+				err = router.GenerateAndExposeOpenapi()
+				require.NoError(t, err)
+
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+				r.ServeHTTP(w, req)
+				require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+				body := readBody(t, w.Result().Body)
+				expected, err := os.ReadFile(test.fixturesPath)
+				require.NoError(t, err)
+				require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+			})
+		}
+	})
+
+	t.Run("dive tags of named types do not change their components", func(t *testing.T) {
+		r := mux.NewRouter()
+		router := newTestRouter(t, r, Options{
+			ValidateTags:        true,
+			UseSchemaComponents: true,
+		})
+
+		_, err := router.AddRoute(http.MethodPost, "/names", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: validatedNames{}},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = router.AddRoute(http.MethodPost, "/aliases", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: validatedAliases{}},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		err = router.GenerateAndExposeOpenapi()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, DefaultJSONDocumentationPath, nil)
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		body := readBody(t, w.Result().Body)
+		expected, err := os.ReadFile("testdata/validate-tags-dive-components.json")
+		require.NoError(t, err)
+		require.JSONEq(t, string(expected), body, "actual json data: %s", body)
+	})
+
+	type unsupportedTags struct {
+		Password string `json:"password" validate:"required,min=8"`
+		Confirm  string `json:"confirm" validate:"eqfield=Password"`
+		Nested   struct {
+			Value string `json:"value" validate:"required_if=Password foo"`
+		} `json:"nested"`
+		Kind string `json:"kind" validate:"rgb|rgba"`
+		Age  int    `json:"age" validate:"email,lowercase"`
+	}
+
+	t.Run("fails with unsupported validate tags", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			ValidateTags: true,
+		})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: unsupportedTags{}},
+				},
+			},
+		})
+		require.EqualError(t, err, "errors generating request body schema: POST /users: request body: unsupported validate tags: eqfield=Password (field Confirm), required_if=Password foo (field Value), rgb|rgba (field Kind), email (field Age), lowercase (field Age)")
+	})
+
+	t.Run("unsupported validate tags handler", func(t *testing.T) {
+		unsupported := []string{}
+		router := newTestRouter(t, mux.NewRouter(), Options{
+			ValidateTags: true,
+			OnUnsupportedValidateTag: func(field reflect.StructField, tag string) error {
+				unsupported = append(unsupported, field.Name+":"+tag)
+				return nil
+			},
+		})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: unsupportedTags{}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Confirm:eqfield=Password", "Value:required_if=Password foo", "Kind:rgb|rgba", "Age:email", "Age:lowercase"}, unsupported)
+	})
+
+	t.Run("validate tags are ignored if not enabled", func(t *testing.T) {
+		router := newTestRouter(t, mux.NewRouter(), Options{})
+
+		_, err := router.AddRoute(http.MethodPost, "/users", okHandler, Definitions{
+			RequestBody: &ContentValue{
+				Content: Content{
+					jsonType: {Value: unsupportedTags{}},
+				},
+			},
+		})
+		require.NoError(t, err)
+	})
+}